@@ -0,0 +1,32 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+)
+
+// The calendar repeats the same days of the week and leap years every 28 years between 1901 and 2099,
+// so the days when an expression runs in the 28 years from cycleStart are a model for any other year
+var (
+	cycleStart = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+	cycleDays  = int(time.Date(2029, time.January, 1, 0, 0, 0, 0, time.UTC).Sub(cycleStart).Hours() / 24)
+)
+
+// runDays returns the days, counted from start, when the expression runs in the next n days.
+// The days are computed in UTC
+func runDays(r *parsers.CronResults, start time.Time, n int) []int {
+	times := dayMinutes(r)
+	res := []int{}
+	if len(times) == 0 {
+		return res
+	}
+	first := time.Duration(times[0]) * time.Minute
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	for d := 0; d < n; d++ {
+		if r.Matches(start.AddDate(0, 0, d).Add(first)) {
+			res = append(res, d)
+		}
+	}
+	return res
+}
@@ -0,0 +1,49 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+	"github.com/reclaro/cep/utils"
+)
+
+// DSTPolicies are all the DST policies in the order used by the reports
+var DSTPolicies = []parsers.DSTPolicy{parsers.DSTSkip, parsers.DSTRunOnce, parsers.DSTRunTwice, parsers.DSTShiftForward}
+
+// DSTEvent is a run of a cron expression whose wall clock is skipped or repeated by a change of the offset
+// of the time zone
+type DSTEvent struct {
+	// Wall is the date and the time of the run, its location is UTC but only the wall clock is meaningful
+	Wall time.Time
+	// Transition is the instant when the offset changes
+	Transition time.Time
+	// Skipped is true if the wall clock does not exist, false if it happens twice
+	Skipped bool
+	// Runs contains the instants when the job runs for every DST policy
+	Runs map[parsers.DSTPolicy][]time.Time
+}
+
+// DSTReport returns all the runs of the cron expression in the year that are affected by a change of the
+// offset of the location
+func DSTReport(r *parsers.CronResults, loc *time.Location, year int) []DSTEvent {
+	events := []DSTEvent{}
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	for _, transition := range utils.Transitions(start, start.AddDate(1, 0, 0)) {
+		_, before := transition.Add(-time.Minute).Zone()
+		_, after := transition.Zone()
+		// the wall clock of the transition with the offset before and after the change
+		from := transition.UTC().Add(time.Duration(before) * time.Second)
+		to := transition.UTC().Add(time.Duration(after) * time.Second)
+		if after < before {
+			from, to = to, from
+		}
+		for _, w := range parsers.Between(r, from, to) {
+			e := DSTEvent{Wall: w, Transition: transition.In(loc), Skipped: after > before, Runs: map[parsers.DSTPolicy][]time.Time{}}
+			for _, p := range DSTPolicies {
+				e.Runs[p] = p.Resolve(w, loc)
+			}
+			events = append(events, e)
+		}
+	}
+	return events
+}
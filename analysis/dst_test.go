@@ -0,0 +1,37 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/internal/testutil"
+	"github.com/reclaro/cep/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDSTReport(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+	events := DSTReport(testutil.Results(t, "30 2 * * * cmd"), loc, 2027)
+	require.Len(t, events, 2)
+
+	// the 28th of March 2027 the clock moves from 02:00 to 03:00
+	skipped := events[0]
+	assert.True(t, skipped.Skipped)
+	assert.Equal(t, time.Date(2027, time.March, 28, 2, 30, 0, 0, time.UTC), skipped.Wall)
+	assert.Equal(t, "2027-03-28 03:00 CEST", skipped.Transition.Format("2006-01-02 15:04 MST"))
+	assert.Empty(t, skipped.Runs[parsers.DSTSkip])
+	assert.Equal(t, "03:00 CEST", skipped.Runs[parsers.DSTRunOnce][0].Format("15:04 MST"))
+	assert.Equal(t, "03:30 CEST", skipped.Runs[parsers.DSTShiftForward][0].Format("15:04 MST"))
+
+	// the 31st of October 2027 the clock moves from 03:00 back to 02:00
+	repeated := events[1]
+	assert.False(t, repeated.Skipped)
+	assert.Equal(t, time.Date(2027, time.October, 31, 2, 30, 0, 0, time.UTC), repeated.Wall)
+	assert.Len(t, repeated.Runs[parsers.DSTSkip], 1)
+	assert.Len(t, repeated.Runs[parsers.DSTRunTwice], 2)
+
+	assert.Empty(t, DSTReport(testutil.Results(t, "30 4 * * * cmd"), loc, 2027))
+	assert.Empty(t, DSTReport(testutil.Results(t, "30 2 * * * cmd"), time.UTC, 2027))
+}
@@ -0,0 +1,121 @@
+package analysis
+
+import (
+	"fmt"
+
+	"github.com/reclaro/cep/parsers"
+)
+
+// minutesPerDay is the number of slots used to compute the load of a set of jobs
+const minutesPerDay = 24 * 60
+
+/*
+Load contains the number of jobs that start at every minute of the day, the index is the minute of the
+day (hour*60 + minute).
+The load only takes into account the Minute and Hour fields, a job that runs only on Mondays is counted
+as a job that runs every day. This is the worst case for the days where all the jobs run together.
+*/
+type Load [minutesPerDay]int
+
+// HotSpot is a minute of the day where many jobs start at the same time
+type HotSpot struct {
+	// Minute is the minute of the day (hour*60 + minute)
+	Minute int
+	// Jobs is the number of jobs that start in that minute
+	Jobs int
+}
+
+// String returns the hot spot in the format HH:MM (n jobs)
+func (h HotSpot) String() string {
+	return fmt.Sprintf("%s (%d jobs)", FormatMinute(h.Minute), h.Jobs)
+}
+
+// Summary describes the distribution of the load over the day
+type Summary struct {
+	// Jobs is the number of job starts in a day
+	Jobs int
+	// Peak is the max number of jobs that start in the same minute
+	Peak int
+	// PeakMinutes are the minutes of the day where the load is equal to Peak
+	PeakMinutes []int
+	// BusyMinutes is the number of minutes of the day where at least a job starts
+	BusyMinutes int
+	// Average is the average number of jobs that start in a busy minute
+	Average float64
+}
+
+// NewLoad computes the load of a set of parsed cron expressions
+func NewLoad(results []*parsers.CronResults) *Load {
+	l := &Load{}
+	for _, r := range results {
+		l.Add(dayMinutes(r), 1)
+	}
+	return l
+}
+
+// Add adds delta jobs to every minute of the day in the input
+func (l *Load) Add(minutes []int, delta int) {
+	for _, m := range minutes {
+		l[m] += delta
+	}
+}
+
+// HotSpots returns the minutes of the day where at least threshold jobs start, in ascending order
+func (l *Load) HotSpots(threshold int) []HotSpot {
+	res := []HotSpot{}
+	for m, jobs := range l {
+		if jobs > 0 && jobs >= threshold {
+			res = append(res, HotSpot{Minute: m, Jobs: jobs})
+		}
+	}
+	return res
+}
+
+// Summary returns the summary of the load
+func (l *Load) Summary() Summary {
+	s := Summary{PeakMinutes: []int{}}
+	for m, jobs := range l {
+		if jobs == 0 {
+			continue
+		}
+		s.Jobs += jobs
+		s.BusyMinutes++
+		if jobs > s.Peak {
+			s.Peak = jobs
+			s.PeakMinutes = s.PeakMinutes[:0]
+		}
+		if jobs == s.Peak {
+			s.PeakMinutes = append(s.PeakMinutes, m)
+		}
+	}
+	if s.BusyMinutes > 0 {
+		s.Average = float64(s.Jobs) / float64(s.BusyMinutes)
+	}
+	return s
+}
+
+// cost returns the sum of the squares of the load, the lower the value the more the jobs are spread
+func (l *Load) cost() int {
+	c := 0
+	for _, jobs := range l {
+		c += jobs * jobs
+	}
+	return c
+}
+
+// dayMinutes returns the minutes of the day (hour*60 + minute) where the cron expression runs,
+// in ascending order
+func dayMinutes(r *parsers.CronResults) []int {
+	res := []int{}
+	for _, h := range r.Hour {
+		for _, m := range r.Minute {
+			res = append(res, h*60+m)
+		}
+	}
+	return res
+}
+
+// FormatMinute formats a minute of the day as HH:MM
+func FormatMinute(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
@@ -0,0 +1,33 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/reclaro/cep/parsers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSummary(t *testing.T) {
+	results := []*parsers.CronResults{
+		{Minute: []int{0, 30}, Hour: []int{1}},
+		{Minute: []int{0}, Hour: []int{1, 2}},
+	}
+	l := NewLoad(results)
+	s := l.Summary()
+	assert.Equal(t, 4, s.Jobs)
+	assert.Equal(t, 2, s.Peak)
+	assert.Equal(t, []int{60}, s.PeakMinutes)
+	assert.Equal(t, 3, s.BusyMinutes)
+	assert.InDelta(t, 1.33, s.Average, 0.01)
+}
+
+func TestLoadHotSpots(t *testing.T) {
+	results := []*parsers.CronResults{
+		{Minute: []int{0, 30}, Hour: []int{1}},
+		{Minute: []int{0}, Hour: []int{1, 2}},
+	}
+	l := NewLoad(results)
+	expected := []HotSpot{{Minute: 60, Jobs: 2}}
+	assert.Equal(t, expected, l.HotSpots(2))
+	assert.Equal(t, "01:00 (2 jobs)", expected[0].String())
+}
@@ -0,0 +1,186 @@
+package analysis
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/reclaro/cep/crontab"
+	"github.com/reclaro/cep/parsers"
+)
+
+// Annotations used in the crontab comments to describe a job
+const (
+	// nameAnnotation is the name of the job, e.g. # cep:name=backup
+	nameAnnotation = "name"
+	// durationAnnotation is the expected duration of the job, e.g. # cep:duration=20m
+	durationAnnotation = "duration"
+	// lockAnnotation is the name of a lock shared by jobs that must not run together, e.g. # cep:lock=db
+	lockAnnotation = "lock"
+)
+
+// Job is a parsed cron expression with the expected duration of its runs
+type Job struct {
+	// Name identifies the job in the reports
+	Name string
+	// Results contains the expanded values of the cron expression
+	Results *parsers.CronResults
+	// Duration is the expected duration of a run
+	Duration time.Duration
+	// Lock is the name of the lock held by the job while it runs, empty if the job does not use a lock
+	Lock string
+}
+
+// SelfOverlap describes if the runs of a job overlap with each other
+type SelfOverlap struct {
+	Job *Job
+	// MinGap is the minimum time between two consecutive runs
+	MinGap time.Duration
+	// Overlaps is true if the job can still be running when the next run starts
+	Overlaps bool
+}
+
+// LockConflict describes two jobs sharing a lock that run at the same time
+type LockConflict struct {
+	Lock   string
+	First  *Job
+	Second *Job
+	// At is the first time when a run of a job starts while the other one is still running
+	At time.Time
+	// Count is the number of conflicts found in the analysed period
+	Count int
+}
+
+// OverlapReport is the result of the overlap analysis
+type OverlapReport struct {
+	// Jobs contains the self overlap analysis of every job, in the same order as the input jobs
+	Jobs []SelfOverlap
+	// Conflicts contains the conflicts between jobs that share a lock
+	Conflicts []LockConflict
+}
+
+/*
+JobsFromCrontab returns the jobs of a crontab file. The name, the duration and the lock of a job are
+read from the annotations of the entry, when the duration annotation is missing defaultDuration is used.
+Jobs without a name annotation are called after their line in the file.
+*/
+func JobsFromCrontab(f *crontab.File, defaultDuration time.Duration) ([]*Job, error) {
+	jobs := []*Job{}
+	for _, e := range f.Entries {
+		j := &Job{
+			Name:     e.Annotations[nameAnnotation],
+			Results:  e.Results,
+			Duration: defaultDuration,
+			Lock:     e.Annotations[lockAnnotation],
+		}
+		if j.Name == "" {
+			j.Name = fmt.Sprintf("line %d", e.Line)
+		}
+		if d, ok := e.Annotations[durationAnnotation]; ok {
+			duration, err := time.ParseDuration(d)
+			if err != nil || duration < 0 {
+				return nil, errors.New(fmt.Sprintf("Invalid duration '%s' for the job at line %d", d, e.Line))
+			}
+			j.Duration = duration
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+/*
+MinGap returns the minimum time between two consecutive runs of a cron expression, it returns 0 if the
+expression runs at most once.
+The gap is computed from the expanded fields: the minimum is either between two runs in the same day
+or between the last run of a day and the first run of the next day when the expression runs.
+*/
+func MinGap(r *parsers.CronResults) time.Duration {
+	gap, _ := dayGaps(dayMinutes(r), runDays(r, cycleStart, cycleDays))
+	if gap < 0 {
+		return 0
+	}
+	return time.Duration(gap) * time.Minute
+}
+
+/*
+Overlaps checks every job against itself and against the other jobs with the same lock.
+A job overlaps with itself if its duration is longer than the minimum gap between its runs.
+The conflicts between jobs sharing a lock are searched in the runs that start in the period from
+start to start+horizon.
+*/
+func Overlaps(jobs []*Job, start time.Time, horizon time.Duration) *OverlapReport {
+	report := &OverlapReport{Jobs: []SelfOverlap{}, Conflicts: []LockConflict{}}
+	locks := map[string][]*Job{}
+	lockNames := []string{}
+	for _, j := range jobs {
+		gap := MinGap(j.Results)
+		report.Jobs = append(report.Jobs, SelfOverlap{Job: j, MinGap: gap, Overlaps: gap > 0 && j.Duration > gap})
+		if j.Lock == "" {
+			continue
+		}
+		if _, ok := locks[j.Lock]; !ok {
+			lockNames = append(lockNames, j.Lock)
+		}
+		locks[j.Lock] = append(locks[j.Lock], j)
+	}
+	sort.Strings(lockNames)
+	for _, name := range lockNames {
+		report.Conflicts = append(report.Conflicts, lockConflicts(name, locks[name], start, horizon)...)
+	}
+	return report
+}
+
+// run is a single execution of a job
+type run struct {
+	job   int
+	start time.Time
+	end   time.Time
+}
+
+// lockConflicts returns the conflicts between the runs of jobs that share the same lock
+func lockConflicts(lock string, jobs []*Job, start time.Time, horizon time.Duration) []LockConflict {
+	res := []LockConflict{}
+	if len(jobs) < 2 {
+		return res
+	}
+	end := start.Add(horizon)
+	runs := []run{}
+	for i, j := range jobs {
+		for _, t := range parsers.Between(j.Results, start, end) {
+			runs = append(runs, run{job: i, start: t, end: t.Add(j.Duration)})
+		}
+	}
+	sort.SliceStable(runs, func(a, b int) bool { return runs[a].start.Before(runs[b].start) })
+
+	// conflicts maps a pair of jobs to the position of their conflict in res
+	conflicts := map[[2]int]int{}
+	active := []run{}
+	for _, r := range runs {
+		// we keep only the runs that are still in progress when r starts
+		running := active[:0]
+		for _, a := range active {
+			if a.end.After(r.start) {
+				running = append(running, a)
+			}
+		}
+		active = running
+		for _, a := range active {
+			if a.job == r.job {
+				continue
+			}
+			pair := [2]int{a.job, r.job}
+			if pair[0] > pair[1] {
+				pair[0], pair[1] = pair[1], pair[0]
+			}
+			if i, ok := conflicts[pair]; ok {
+				res[i].Count++
+				continue
+			}
+			conflicts[pair] = len(res)
+			res = append(res, LockConflict{Lock: lock, First: jobs[pair[0]], Second: jobs[pair[1]], At: r.start, Count: 1})
+		}
+		active = append(active, r)
+	}
+	return res
+}
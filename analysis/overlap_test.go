@@ -0,0 +1,79 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/crontab"
+	"github.com/reclaro/cep/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinGap(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected time.Duration
+	}{
+		{"every 15 minutes", "*/15 * * * * cmd", 15 * time.Minute},
+		{"irregular minutes", "0,10,50 * * * * cmd", 10 * time.Minute},
+		{"across midnight", "0 1,23 * * * cmd", 2 * time.Hour},
+		{"weekly", "0 0 * * 1 cmd", 7 * 24 * time.Hour},
+		{"first and last of the month", "0 0 1,31 * * cmd", 24 * time.Hour},
+		{"leap day", "0 0 29 2 * cmd", (4*365 + 1) * 24 * time.Hour},
+		{"never", "0 0 30 2 * cmd", 0},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, MinGap(testutil.Results(t, tc.input)))
+		})
+	}
+}
+
+func TestJobsFromCrontab(t *testing.T) {
+	input := `# cep:name=backup cep:duration=20m cep:lock=db
+*/15 * * * * /bin/backup
+0 * * * * /bin/report
+`
+	f, err := crontab.Parse(strings.NewReader(input))
+	require.Nil(t, err)
+	jobs, err := JobsFromCrontab(f, time.Minute)
+	require.Nil(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "backup", jobs[0].Name)
+	assert.Equal(t, 20*time.Minute, jobs[0].Duration)
+	assert.Equal(t, "db", jobs[0].Lock)
+	assert.Equal(t, "line 3", jobs[1].Name)
+	assert.Equal(t, time.Minute, jobs[1].Duration)
+
+	f, err = crontab.Parse(strings.NewReader("# cep:duration=soon\n* * * * * cmd"))
+	require.Nil(t, err)
+	_, err = JobsFromCrontab(f, 0)
+	assert.NotNil(t, err)
+}
+
+func TestOverlaps(t *testing.T) {
+	jobs := []*Job{
+		{Name: "backup", Results: testutil.Results(t, "*/15 * * * * cmd"), Duration: 20 * time.Minute, Lock: "db"},
+		{Name: "report", Results: testutil.Results(t, "5 * * * * cmd"), Duration: 5 * time.Minute, Lock: "db"},
+		{Name: "vacuum", Results: testutil.Results(t, "50 * * * * cmd"), Duration: time.Minute, Lock: "other"},
+	}
+	start := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	report := Overlaps(jobs, start, 2*time.Hour)
+
+	require.Len(t, report.Jobs, 3)
+	assert.True(t, report.Jobs[0].Overlaps)
+	assert.Equal(t, 15*time.Minute, report.Jobs[0].MinGap)
+	assert.False(t, report.Jobs[1].Overlaps)
+
+	require.Len(t, report.Conflicts, 1)
+	c := report.Conflicts[0]
+	assert.Equal(t, "db", c.Lock)
+	assert.Equal(t, "backup", c.First.Name)
+	assert.Equal(t, "report", c.Second.Name)
+	assert.Equal(t, time.Date(2021, time.March, 1, 0, 5, 0, 0, time.UTC), c.At)
+	assert.Equal(t, 2, c.Count)
+}
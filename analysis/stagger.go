@@ -0,0 +1,153 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/reclaro/cep/crontab"
+	"github.com/reclaro/cep/parsers"
+	"github.com/reclaro/cep/utils"
+)
+
+// maxShift is the max number of minutes a job is moved earlier or later by the optimizer
+const maxShift = 30
+
+// Change is a rewrite of the time fields of a crontab entry proposed by the optimizer
+type Change struct {
+	// Line is the line of the entry in the crontab file
+	Line int
+	// Shift is the number of minutes the job has been moved, negative values mean earlier
+	Shift int
+	// Before contains the time fields before the change
+	Before []string
+	// After contains the proposed time fields
+	After []string
+}
+
+// Plan is the result of the optimizer
+type Plan struct {
+	// Patched is a copy of the input crontab with the proposed changes applied
+	Patched *crontab.File
+	// Changes are the proposed changes in the order of the lines of the file
+	Changes []Change
+	// Before is the load summary of the input crontab
+	Before Summary
+	// After is the load summary of the patched crontab
+	After Summary
+}
+
+/*
+Stagger proposes new Minute and Hour fields for the entries of a crontab so that the jobs are spread as
+evenly as possible over the day.
+Every job keeps its frequency: all its run times are moved by the same number of minutes, at most
+maxShift minutes earlier or later, and a move is accepted only when the result can still be expressed
+with a Minute and an Hour field. The Day of month, Month and Day of week fields are never changed.
+The jobs are placed one by one starting from the ones that run most often, each job gets the shift
+that gives the lowest peak and then the lowest sum of the squares of the load. On ties the smallest
+shift wins, so a job is moved only if that improves the load.
+*/
+func Stagger(f *crontab.File) (*Plan, error) {
+	patched := f.Clone()
+	results := []*parsers.CronResults{}
+	for _, e := range f.Entries {
+		results = append(results, e.Results)
+	}
+	plan := &Plan{Patched: patched, Changes: []Change{}, Before: NewLoad(results).Summary()}
+
+	entries := append([]*crontab.Entry{}, patched.Entries...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return len(dayMinutes(entries[i].Results)) > len(dayMinutes(entries[j].Results))
+	})
+
+	load := &Load{}
+	for _, e := range entries {
+		original := dayMinutes(e.Results)
+		bestShift, bestPeak, bestCost := 0, 0, 0
+		for _, shift := range shifts() {
+			minutes, ok := shiftMinutes(original, shift, allDays(e.Results))
+			if !ok {
+				continue
+			}
+			load.Add(minutes, 1)
+			peak, cost := load.Summary().Peak, load.cost()
+			load.Add(minutes, -1)
+			if shift == 0 || peak < bestPeak || (peak == bestPeak && cost < bestCost) {
+				bestShift, bestPeak, bestCost = shift, peak, cost
+			}
+		}
+		minutes, _ := shiftMinutes(original, bestShift, true)
+		load.Add(minutes, 1)
+		if bestShift == 0 {
+			continue
+		}
+		fields := append([]string{}, e.Fields...)
+		mins, hours := splitMinutes(minutes)
+		fields[0] = utils.CompactValues(mins, []int{0, 59})
+		if !equalInts(hours, e.Results.Hour) {
+			fields[1] = utils.CompactValues(hours, []int{0, 23})
+		}
+		before := e.Fields
+		if err := patched.SetFields(e, fields); err != nil {
+			return nil, err
+		}
+		plan.Changes = append(plan.Changes, Change{Line: e.Line, Shift: bestShift, Before: before, After: e.Fields})
+	}
+	sort.Slice(plan.Changes, func(i, j int) bool { return plan.Changes[i].Line < plan.Changes[j].Line })
+	plan.After = load.Summary()
+	return plan, nil
+}
+
+// shifts returns the shifts to try, in order of distance from the original time
+func shifts() []int {
+	res := []int{0}
+	for d := 1; d <= maxShift; d++ {
+		res = append(res, d, -d)
+	}
+	return res
+}
+
+// shiftMinutes moves all the minutes of the day by shift minutes. It returns false if the result is not the
+// product of a set of minutes and a set of hours or if a run is moved to another day and wrap is false
+func shiftMinutes(minutes []int, shift int, wrap bool) ([]int, bool) {
+	res := make([]int, 0, len(minutes))
+	for _, m := range minutes {
+		s := m + shift
+		if s < 0 || s >= minutesPerDay {
+			if !wrap {
+				return nil, false
+			}
+			s = (s + minutesPerDay) % minutesPerDay
+		}
+		res = append(res, s)
+	}
+	res = utils.SortedUniqueInts(res)
+	mins, hours := splitMinutes(res)
+	return res, len(mins)*len(hours) == len(res)
+}
+
+// splitMinutes returns the sorted minutes and hours that appear in a list of minutes of the day
+func splitMinutes(minutes []int) ([]int, []int) {
+	mins, hours := []int{}, []int{}
+	for _, m := range minutes {
+		mins = append(mins, m%60)
+		hours = append(hours, m/60)
+	}
+	return utils.SortedUniqueInts(mins), utils.SortedUniqueInts(hours)
+}
+
+// allDays returns true if the job runs every day, in that case a run can be moved across midnight
+func allDays(r *parsers.CronResults) bool {
+	return len(r.DayMonth) == 31 && len(r.Month) == 12 && len(r.DayWeek) == 7
+}
+
+// equalInts returns true if the two arrays contain the same values in the same order
+func equalInts(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
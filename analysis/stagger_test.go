@@ -0,0 +1,65 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/reclaro/cep/crontab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagger(t *testing.T) {
+	input := `# nightly jobs
+0 3 * * * /bin/backup
+0 3 * * * /bin/cleanup
+*/15 * * * * /bin/poll
+0 3 * * 1-5 /bin/report
+`
+	f, err := crontab.Parse(strings.NewReader(input))
+	require.Nil(t, err)
+
+	plan, err := Stagger(f)
+	require.Nil(t, err)
+	assert.Equal(t, 4, plan.Before.Peak)
+	assert.Equal(t, 1, plan.After.Peak)
+	// the number of runs does not change
+	assert.Equal(t, plan.Before.Jobs, plan.After.Jobs)
+
+	// the job that runs most often is placed first and it is not moved
+	assert.Equal(t, []string{"*/15", "*", "*", "*", "*"}, plan.Patched.Entries[2].Fields)
+	require.Len(t, plan.Changes, 3)
+	assert.Equal(t, 2, plan.Changes[0].Line)
+	assert.Equal(t, []string{"1", "3", "*", "*", "*"}, plan.Changes[0].After)
+	assert.Equal(t, 3, plan.Changes[1].Line)
+	assert.Equal(t, -1, plan.Changes[1].Shift)
+	assert.Equal(t, []string{"59", "2", "*", "*", "*"}, plan.Changes[1].After)
+	assert.Equal(t, 5, plan.Changes[2].Line)
+	assert.Equal(t, []string{"2", "3", "*", "*", "1-5"}, plan.Changes[2].After)
+	assert.True(t, strings.HasPrefix(plan.Patched.String(), "# nightly jobs\n1 3 * * * /bin/backup\n59 2 * * * /bin/cleanup\n"))
+	// the input file is not changed
+	assert.Equal(t, "0", f.Entries[0].Fields[0])
+}
+
+func TestStaggerNoChanges(t *testing.T) {
+	input := "0 3 * * * /bin/backup\n30 3 * * * /bin/cleanup\n"
+	f, err := crontab.Parse(strings.NewReader(input))
+	require.Nil(t, err)
+
+	plan, err := Stagger(f)
+	require.Nil(t, err)
+	assert.Empty(t, plan.Changes)
+	assert.Equal(t, input, plan.Patched.String())
+}
+
+func TestShiftMinutes(t *testing.T) {
+	// 23:50 moved 20 minutes later is on the next day
+	_, ok := shiftMinutes([]int{23*60 + 50}, 20, false)
+	assert.False(t, ok)
+	res, ok := shiftMinutes([]int{23*60 + 50}, 20, true)
+	assert.True(t, ok)
+	assert.Equal(t, []int{10}, res)
+	// 01:30 and 01:50 moved 20 minutes later cannot be expressed by a Minute and an Hour field
+	_, ok = shiftMinutes([]int{60 + 30, 60 + 50}, 20, false)
+	assert.False(t, ok)
+}
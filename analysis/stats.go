@@ -0,0 +1,118 @@
+package analysis
+
+import (
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+)
+
+// Stats contains the statistics of the runs of a cron expression
+type Stats struct {
+	// MinGap is the minimum time between two consecutive runs
+	MinGap time.Duration
+	// MaxGap is the maximum time between two consecutive runs
+	MaxGap time.Duration
+	// AverageGap is the average time between two consecutive runs
+	AverageGap time.Duration
+	// RunsPerDay is the number of runs in a day when the expression runs
+	RunsPerDay int
+	// RunsPerWeek is the average number of runs in a week
+	RunsPerWeek float64
+	// RunsPerMonth is the average number of runs in a month
+	RunsPerMonth float64
+	// RunsPerYear is the average number of runs in a year
+	RunsPerYear float64
+	// Year is the year used to find the longest idle period
+	Year int
+	// IdleStart and IdleEnd are the runs that delimit the longest idle period that starts in Year,
+	// they are zero if the expression does not run in Year
+	IdleStart time.Time
+	IdleEnd   time.Time
+}
+
+/*
+NewStats computes the statistics of a cron expression. The times are in UTC.
+The gaps and the averages are not computed iterating over all the runs: the runs in a day are given by the
+Minute and Hour fields, so only the days when the expression runs are needed. The days are taken from
+the 28 years cycle of the calendar, that is a model for all the years between 1901 and 2099.
+*/
+func NewStats(r *parsers.CronResults, year int) Stats {
+	times := dayMinutes(r)
+	days := runDays(r, cycleStart, cycleDays)
+	s := Stats{Year: year, RunsPerDay: len(times)}
+	if len(days) == 0 {
+		s.RunsPerDay = 0
+		return s
+	}
+	minGap, maxGap := dayGaps(times, days)
+	s.MinGap = time.Duration(minGap) * time.Minute
+	s.MaxGap = time.Duration(maxGap) * time.Minute
+
+	runs := len(days) * len(times)
+	years := float64(cycleDays) / 365.25
+	s.RunsPerYear = float64(runs) / years
+	s.RunsPerMonth = s.RunsPerYear / 12
+	s.RunsPerWeek = s.RunsPerYear * 7 / 365.25
+	if runs > 1 {
+		span := (days[len(days)-1]-days[0])*minutesPerDay + times[len(times)-1] - times[0]
+		s.AverageGap = (time.Duration(span) * time.Minute / time.Duration(runs-1)).Round(time.Second)
+	}
+
+	s.IdleStart, s.IdleEnd = longestIdle(r, times, year)
+	return s
+}
+
+// dayGaps returns the minimum and the maximum gap in minutes between two runs, given the minutes of the day
+// and the days when the expression runs. The gaps are -1 if there are not two runs
+func dayGaps(times []int, days []int) (int, int) {
+	minGap, maxGap := -1, -1
+	update := func(gap int) {
+		if minGap < 0 || gap < minGap {
+			minGap = gap
+		}
+		if gap > maxGap {
+			maxGap = gap
+		}
+	}
+	if len(days) > 0 {
+		for i := 1; i < len(times); i++ {
+			update(times[i] - times[i-1])
+		}
+	}
+	for i := 1; i < len(days); i++ {
+		update((days[i]-days[i-1])*minutesPerDay - times[len(times)-1] + times[0])
+	}
+	return minGap, maxGap
+}
+
+// longestIdle returns the first and the last run of the longest period without runs that starts in the year,
+// the period can end in one of the following years
+func longestIdle(r *parsers.CronResults, times []int, year int) (time.Time, time.Time) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearDays := int(start.AddDate(1, 0, 0).Sub(start).Hours() / 24)
+	// we look into the following years to find the run after the last one of the year, the max distance
+	// between two runs is 8 years for the 29th of February
+	days := runDays(r, start, yearDays+8*366)
+	var idleStart, idleEnd time.Time
+	maxGap := -1
+	at := func(day int, minute int) time.Time {
+		return start.AddDate(0, 0, day).Add(time.Duration(minute) * time.Minute)
+	}
+	for i, d := range days {
+		if d >= yearDays {
+			break
+		}
+		for j := 1; j < len(times); j++ {
+			if gap := times[j] - times[j-1]; gap > maxGap {
+				maxGap, idleStart, idleEnd = gap, at(d, times[j-1]), at(d, times[j])
+			}
+		}
+		if i+1 < len(days) {
+			gap := (days[i+1]-d)*minutesPerDay - times[len(times)-1] + times[0]
+			if gap > maxGap {
+				maxGap, idleStart, idleEnd = gap, at(d, times[len(times)-1]), at(days[i+1], times[0])
+			}
+		}
+	}
+	return idleStart, idleEnd
+}
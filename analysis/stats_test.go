@@ -0,0 +1,53 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/crontab"
+	"github.com/reclaro/cep/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsRegular(t *testing.T) {
+	s := NewStats(testutil.Results(t, "*/15 * * * * cmd"), 2021)
+	assert.Equal(t, 15*time.Minute, s.MinGap)
+	assert.Equal(t, 15*time.Minute, s.MaxGap)
+	assert.Equal(t, 15*time.Minute, s.AverageGap)
+	assert.Equal(t, 96, s.RunsPerDay)
+	assert.InDelta(t, 96*7, s.RunsPerWeek, 0.01)
+	assert.InDelta(t, 96*365.25, s.RunsPerYear, 0.01)
+}
+
+func TestStatsIrregular(t *testing.T) {
+	s := NewStats(testutil.Results(t, "0 0 1,31 * * cmd"), 2021)
+	assert.Equal(t, 24*time.Hour, s.MinGap)
+	assert.Equal(t, 30*24*time.Hour, s.MaxGap)
+	assert.Equal(t, 1, s.RunsPerDay)
+	assert.InDelta(t, 19, s.RunsPerYear, 0.01)
+	// the first idle period of 30 days in 2021 is from the 1st to the 31st of January
+	assert.Equal(t, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), s.IdleStart)
+	assert.Equal(t, time.Date(2021, time.January, 31, 0, 0, 0, 0, time.UTC), s.IdleEnd)
+}
+
+func TestStatsWithoutCommand(t *testing.T) {
+	// cep stats "0 0 1,31 * *"
+	res, err := crontab.ExpandSchedule("0 0 1,31 * *")
+	require.Nil(t, err)
+	assert.Equal(t, NewStats(testutil.Results(t, "0 0 1,31 * * cmd"), 2021), NewStats(res, 2021))
+}
+
+func TestStatsLongestIdleAcrossYears(t *testing.T) {
+	s := NewStats(testutil.Results(t, "0 12 29 2 * cmd"), 2024)
+	assert.Equal(t, time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC), s.IdleStart)
+	assert.Equal(t, time.Date(2028, time.February, 29, 12, 0, 0, 0, time.UTC), s.IdleEnd)
+	// there are no runs in 2025
+	s = NewStats(testutil.Results(t, "0 12 29 2 * cmd"), 2025)
+	assert.True(t, s.IdleStart.IsZero())
+}
+
+func TestStatsNever(t *testing.T) {
+	s := NewStats(testutil.Results(t, "0 0 30 2 * cmd"), 2021)
+	assert.Equal(t, Stats{Year: 2021}, s)
+}
@@ -0,0 +1,138 @@
+package calendars
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+)
+
+// maxBusinessDays is the max number of business days in a month
+const maxBusinessDays = 31
+
+/*
+BusinessDay is a Schedule that runs on the Nth business days of the month, e.g. the 3rd business day or
+the last business day (-1).
+The business days of a month are the days whose day of the week is in the DayWeek field of the cron
+expression and that are not holidays, a day is a holiday if one of the calendars contains its midnight.
+The Minute, Hour and Month fields of the expression are used as they are, while its DayMonth field is
+replaced by the business days, so it must be *.
+*/
+type BusinessDay struct {
+	Results  *parsers.CronResults
+	Nth      []int
+	Holidays []*Calendar
+}
+
+// NewBusinessDay returns a schedule that runs on the nth business days of the month, negative values
+// count from the end of the month
+func NewBusinessDay(cr *parsers.CronResults, nth []int, holidays ...*Calendar) (*BusinessDay, error) {
+	if len(cr.DayMonth) < 31 {
+		return nil, errors.New("The day of the month must be * for a business day schedule")
+	}
+	if len(nth) == 0 {
+		return nil, errors.New("At least a business day is needed")
+	}
+	for _, n := range nth {
+		if n == 0 || n > maxBusinessDays || n < -maxBusinessDays {
+			return nil, errors.New(fmt.Sprintf("Invalid business day %d, allowed values are 1 to %d and -1 to -%d", n, maxBusinessDays, maxBusinessDays))
+		}
+	}
+	return &BusinessDay{Results: cr, Nth: nth, Holidays: holidays}, nil
+}
+
+// IsBusinessDay returns true if the day of t is a business day
+func (b *BusinessDay) IsBusinessDay(t time.Time) bool {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if !containsInt(b.Results.DayWeek, int(t.Weekday())) {
+		return false
+	}
+	for _, c := range b.Holidays {
+		if c.Contains(midnight) {
+			return false
+		}
+	}
+	return true
+}
+
+// DaysOfTheMonth returns the days of the month when the schedule runs, in ascending order. It is the
+// equivalent of the DayMonth field of a cron expression for a specific month
+func (b *BusinessDay) DaysOfTheMonth(year int, month time.Month, loc *time.Location) []int {
+	business := []int{}
+	for d := time.Date(year, month, 1, 0, 0, 0, 0, loc); d.Month() == month; d = d.AddDate(0, 0, 1) {
+		if b.IsBusinessDay(d) {
+			business = append(business, d.Day())
+		}
+	}
+	res := []int{}
+	for i, d := range business {
+		for _, n := range b.Nth {
+			if n == i+1 || n == i-len(business) {
+				res = append(res, d)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// Next returns the first run after t or the zero time if the schedule does not run in the next parsers.MaxYears years
+func (b *BusinessDay) Next(t time.Time) time.Time {
+	loc := t.Location()
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	for i := 0; i < parsers.MaxYears*12; i, month = i+1, month.AddDate(0, 1, 0) {
+		if !containsInt(b.Results.Month, int(month.Month())) {
+			continue
+		}
+		for _, d := range b.DaysOfTheMonth(month.Year(), month.Month(), loc) {
+			for _, h := range b.Results.Hour {
+				for _, m := range b.Results.Minute {
+					run := time.Date(month.Year(), month.Month(), d, h, m, 0, 0, loc)
+					if run.After(t) {
+						return run
+					}
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// Matches returns true if the schedule runs in the minute of t
+func (b *BusinessDay) Matches(t time.Time) bool {
+	if !containsInt(b.Results.Minute, t.Minute()) || !containsInt(b.Results.Hour, t.Hour()) ||
+		!containsInt(b.Results.Month, int(t.Month())) {
+		return false
+	}
+	return containsInt(b.DaysOfTheMonth(t.Year(), t.Month(), t.Location()), t.Day())
+}
+
+// String returns the description of the schedule
+func (b *BusinessDay) String() string {
+	nth := []string{}
+	for _, n := range b.Nth {
+		nth = append(nth, strconv.Itoa(n))
+	}
+	names := []string{}
+	for _, c := range b.Holidays {
+		names = append(names, c.Name)
+	}
+	s := fmt.Sprintf("%s on business days %s", parsers.Describe(b.Results), strings.Join(nth, ","))
+	if len(names) > 0 {
+		s += " with holidays " + strings.Join(names, ", ")
+	}
+	return s
+}
+
+// containsInt returns true if the value is in the input
+func containsInt(input []int, value int) bool {
+	for _, v := range input {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package calendars
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/internal/testutil"
+	"github.com/reclaro/cep/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusinessDaysOfTheMonth(t *testing.T) {
+	holidays, err := Parse(strings.NewReader("2021-04-02\n2021-04-05\n"), "easter")
+	require.Nil(t, err)
+	b, err := NewBusinessDay(testutil.Results(t, "0 9 * * 1-5 cmd"), []int{1, 3, -1}, holidays)
+	require.Nil(t, err)
+
+	// April 2021 starts on Thursday, Friday 2nd and Monday 5th are holidays
+	assert.Equal(t, []int{1, 7, 30}, b.DaysOfTheMonth(2021, time.April, time.UTC))
+	assert.False(t, b.IsBusinessDay(time.Date(2021, time.April, 2, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, b.IsBusinessDay(time.Date(2021, time.April, 3, 12, 0, 0, 0, time.UTC)))
+	assert.True(t, b.IsBusinessDay(time.Date(2021, time.April, 6, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestBusinessDayNext(t *testing.T) {
+	b, err := NewBusinessDay(testutil.Results(t, "0 9 * * 1-5 cmd"), []int{3})
+	require.Nil(t, err)
+
+	from := time.Date(2021, time.April, 30, 0, 0, 0, 0, time.UTC)
+	expected := []time.Time{
+		time.Date(2021, time.May, 5, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, time.June, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, time.July, 5, 9, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, expected, parsers.Upcoming(b, from, 3))
+	assert.True(t, b.Matches(expected[0]))
+	assert.False(t, b.Matches(time.Date(2021, time.May, 4, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "0 9 * * 1-5 on business days 3", b.String())
+}
+
+func TestNewBusinessDayInvalid(t *testing.T) {
+	_, err := NewBusinessDay(testutil.Results(t, "0 9 1 * 1-5 cmd"), []int{3})
+	assert.NotNil(t, err)
+	_, err = NewBusinessDay(testutil.Results(t, "0 9 * * 1-5 cmd"), []int{0})
+	assert.NotNil(t, err)
+	_, err = NewBusinessDay(testutil.Results(t, "0 9 * * 1-5 cmd"), []int{})
+	assert.NotNil(t, err)
+}
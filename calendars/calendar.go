@@ -0,0 +1,250 @@
+package calendars
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rule is a set of minutes of a calendar, the minutes are checked against the wall clock of the time
+type rule interface {
+	contains(t time.Time) bool
+}
+
+/*
+Calendar is a set of periods when the jobs must not run, e.g. company holidays or change freezes.
+A calendar is made of rules, a time is in the calendar if at least one rule contains it.
+The rules use the wall clock of the time they check, so the same calendar works in every time zone.
+*/
+type Calendar struct {
+	Name  string
+	rules []rule
+}
+
+// NewCalendar returns an empty calendar
+func NewCalendar(name string) *Calendar {
+	return &Calendar{Name: name}
+}
+
+// Contains returns true if the minute of t is in the calendar
+func (c *Calendar) Contains(t time.Time) bool {
+	for _, r := range c.rules {
+		if r.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of rules of the calendar
+func (c *Calendar) Len() int {
+	return len(c.rules)
+}
+
+// AddDate adds a whole day to the calendar, if year is 0 the day is added for every year
+func (c *Calendar) AddDate(year int, month time.Month, day int) {
+	c.rules = append(c.rules, date{year: year, month: month, day: day})
+}
+
+// AddRange adds all the minutes from start to end, both included. Only the wall clock of start and end
+// is used, their location is ignored
+func (c *Calendar) AddRange(start time.Time, end time.Time) {
+	c.rules = append(c.rules, period{start: wallMinute(start), end: wallMinute(end)})
+}
+
+// AddInterval adds the instants from start (included) to end (excluded), unlike AddRange the location of
+// start and end is used
+func (c *Calendar) AddInterval(start time.Time, end time.Time) {
+	c.rules = append(c.rules, interval{start: start, end: end})
+}
+
+// AddWeekly adds a window that repeats every week on the day, from the minute of the day from
+// (included) to the minute of the day to (excluded)
+func (c *Calendar) AddWeekly(day time.Weekday, from int, to int) {
+	c.rules = append(c.rules, weekly{day: day, from: from, to: to})
+}
+
+// date is a whole day, every year if year is 0
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func (d date) contains(t time.Time) bool {
+	return (d.year == 0 || d.year == t.Year()) && d.month == t.Month() && d.day == t.Day()
+}
+
+// period contains the minutes between start and end, both included, expressed as wall minutes
+type period struct {
+	start int64
+	end   int64
+}
+
+func (p period) contains(t time.Time) bool {
+	m := wallMinute(t)
+	return m >= p.start && m <= p.end
+}
+
+// weekly contains the minutes of the day in the interval [from, to) of a day of the week
+type weekly struct {
+	day  time.Weekday
+	from int
+	to   int
+}
+
+func (w weekly) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	return t.Weekday() == w.day && m >= w.from && m < w.to
+}
+
+// wallMinute returns the wall clock of t as a number that can be compared, e.g. 202112251830 for
+// the 25th of December 2021 at 18:30
+func wallMinute(t time.Time) int64 {
+	return int64(t.Year())*100000000 + int64(t.Month())*1000000 + int64(t.Day())*10000 + int64(t.Hour())*100 + int64(t.Minute())
+}
+
+var (
+	// dateFormat is the format of the dates in the calendar files
+	dateFormat = "2006-01-02"
+	// dateTimeFormat is the format of the times in the calendar files
+	dateTimeFormat = "2006-01-02T15:04"
+	// annualDate matches the dates that repeat every year, e.g. 12-25
+	annualDate = regexp.MustCompile(`^([0-9]{2})-([0-9]{2})$`)
+	// weeklyWindow matches a weekly window, e.g. Fri 18:00-24:00 or Sat
+	weeklyWindow = regexp.MustCompile(`^([A-Za-z]{3})(?:\s+([0-9]{2}):([0-9]{2})-([0-9]{2}):([0-9]{2}))?$`)
+	// weekDays maps the names of the days of the week used in the calendar files
+	weekDays = map[string]time.Weekday{"SUN": time.Sunday, "MON": time.Monday, "TUE": time.Tuesday,
+		"WED": time.Wednesday, "THU": time.Thursday, "FRI": time.Friday, "SAT": time.Saturday}
+)
+
+// Load reads the calendar file at the given path, the name of the calendar is the name of the file.
+// Files with the .ics extension are read as iCalendar files, the others with the calendar file format
+func Load(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		return ParseICS(f, filepath.Base(path))
+	}
+	return Parse(f, filepath.Base(path))
+}
+
+/*
+Parse reads a calendar from r. The calendar file has a rule on every line, empty lines and lines
+starting with # are ignored. The text after a # is a comment. A rule is one of the following:
+
+	2021-12-25                          a fixed date
+	12-25                               a date that repeats every year
+	2021-12-24..2022-01-02              a range of dates, both included
+	2021-11-05T18:00..2021-11-08T06:00  a range of times, both included
+	Fri 18:00-24:00                     a weekly window, the end time is excluded
+	Sat                                 a whole day of the week
+*/
+func Parse(r io.Reader, name string) (*Calendar, error) {
+	c := NewCalendar(name)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if i := strings.Index(text, "#"); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		if err := c.parseRule(text); err != nil {
+			return nil, fmt.Errorf("%s line %d: %s", name, line, err.Error())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseRule adds to the calendar the rule in the input string
+func (c *Calendar) parseRule(input string) error {
+	if bounds := strings.Split(input, ".."); len(bounds) == 2 {
+		start, startDate, err := parseDateTime(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return err
+		}
+		end, endDate, err := parseDateTime(strings.TrimSpace(bounds[1]))
+		if err != nil {
+			return err
+		}
+		if endDate {
+			// a range of dates includes the whole last day
+			end = end.Add(24*time.Hour - time.Minute)
+		}
+		if end.Before(start) || startDate != endDate {
+			return errors.New(fmt.Sprintf("Invalid range '%s'", input))
+		}
+		c.AddRange(start, end)
+		return nil
+	}
+	if t, err := time.Parse(dateFormat, input); err == nil {
+		c.AddDate(t.Year(), t.Month(), t.Day())
+		return nil
+	}
+	if m := annualDate.FindStringSubmatch(input); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		day, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 || day < 1 || day > 31 {
+			return errors.New(fmt.Sprintf("Invalid date '%s'", input))
+		}
+		c.AddDate(0, time.Month(month), day)
+		return nil
+	}
+	if m := weeklyWindow.FindStringSubmatch(input); m != nil {
+		day, ok := weekDays[strings.ToUpper(m[1])]
+		if !ok {
+			return errors.New(fmt.Sprintf("Invalid day of the week '%s'", m[1]))
+		}
+		if m[2] == "" {
+			c.AddWeekly(day, 0, minutesPerDay)
+			return nil
+		}
+		from := atoi(m[2])*60 + atoi(m[3])
+		to := atoi(m[4])*60 + atoi(m[5])
+		if from >= to || to > minutesPerDay || atoi(m[3]) > 59 || atoi(m[5]) > 59 {
+			return errors.New(fmt.Sprintf("Invalid weekly window '%s'", input))
+		}
+		c.AddWeekly(day, from, to)
+		return nil
+	}
+	return errors.New(fmt.Sprintf("Invalid rule '%s'", input))
+}
+
+// minutesPerDay is the number of minutes in a day
+const minutesPerDay = 24 * 60
+
+// parseDateTime parses a date or a date with a time, it returns true if the input is only a date
+func parseDateTime(input string) (time.Time, bool, error) {
+	if t, err := time.Parse(dateFormat, input); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(dateTimeFormat, input)
+	if err != nil {
+		return t, false, errors.New(fmt.Sprintf("Invalid date '%s'", input))
+	}
+	return t, false, nil
+}
+
+// atoi converts a string made of digits to int
+func atoi(s string) int {
+	v, _ := strconv.Atoi(s)
+	return v
+}
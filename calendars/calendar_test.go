@@ -0,0 +1,70 @@
+package calendars
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `# company holidays
+2021-12-24..2021-12-26
+01-01        # new year
+2021-11-05T18:00..2021-11-08T06:00
+Fri 18:00-24:00
+Sun
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse(strings.NewReader(sample), "holidays")
+	require.Nil(t, err)
+	assert.Equal(t, "holidays", c.Name)
+	assert.Equal(t, 5, c.Len())
+
+	tcs := []struct {
+		name     string
+		time     time.Time
+		expected bool
+	}{
+		{"first day of the range", time.Date(2021, time.December, 24, 0, 0, 0, 0, time.UTC), true},
+		{"last minute of the range", time.Date(2021, time.December, 26, 23, 59, 0, 0, time.UTC), true},
+		{"after the range", time.Date(2021, time.December, 27, 0, 0, 0, 0, time.UTC), false},
+		{"every year", time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC), true},
+		{"time range", time.Date(2021, time.November, 8, 6, 0, 0, 0, time.UTC), true},
+		{"after the time range", time.Date(2021, time.November, 8, 6, 1, 0, 0, time.UTC), false},
+		{"weekly window", time.Date(2021, time.March, 5, 18, 0, 0, 0, time.UTC), true},
+		{"before the weekly window", time.Date(2021, time.March, 5, 17, 59, 0, 0, time.UTC), false},
+		{"whole day of the week", time.Date(2021, time.March, 7, 9, 0, 0, 0, time.UTC), true},
+		{"wall clock in another location", time.Date(2021, time.December, 24, 0, 0, 0, 0, time.FixedZone("X", 3600)), true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, c.Contains(tc.time))
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tcs := []struct {
+		name  string
+		input string
+	}{
+		{"invalid date", "2021-13-01"},
+		{"invalid annual date", "13-01"},
+		{"range end before start", "2021-12-26..2021-12-24"},
+		{"range with date and time", "2021-12-24..2021-12-26T10:00"},
+		{"invalid day", "Xyz"},
+		{"invalid window", "Fri 18:00-17:00"},
+		{"unknown rule", "tomorrow"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.input), "test")
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package calendars
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the dates in the iCalendar files
+const (
+	icsDate        = "20060102"
+	icsDateTime    = "20060102T150405"
+	icsDateTimeUTC = "20060102T150405Z"
+)
+
+// icsProperty is a content line of an iCalendar file, e.g. DTSTART;TZID=Europe/Rome:20211225T090000
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// icsEvent contains the properties of a VEVENT used to build the calendar
+type icsEvent struct {
+	start *icsProperty
+	end   *icsProperty
+	rrule string
+}
+
+/*
+ParseICS reads an iCalendar file (RFC 5545) from r and returns a calendar with the time of its events.
+Only the VEVENT components are used, with the following properties:
+
+	DTSTART and DTEND in the formats date (all-day events), date-time in UTC, date-time with a TZID
+	parameter or floating date-time (wall clock). When DTEND is missing the event lasts a day for
+	all-day events and a minute otherwise.
+	RRULE with FREQ=YEARLY and optionally INTERVAL, COUNT or UNTIL, only for all-day events. BYMONTH and
+	BYMONTHDAY are accepted when they are the month and the day of DTSTART. The years without the day of
+	DTSTART, e.g. the 29th of February, are skipped
+*/
+func ParseICS(r io.Reader, name string) (*Calendar, error) {
+	c := NewCalendar(name)
+	var event *icsEvent
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range lines {
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err.Error())
+		}
+		switch {
+		case p.name == "BEGIN" && p.value == "VEVENT":
+			event = &icsEvent{}
+		case p.name == "END" && p.value == "VEVENT":
+			if event == nil {
+				return nil, errors.New(fmt.Sprintf("%s: END:VEVENT without BEGIN:VEVENT", name))
+			}
+			if err := c.addEvent(event); err != nil {
+				return nil, fmt.Errorf("%s: %s", name, err.Error())
+			}
+			event = nil
+		case event == nil:
+			continue
+		case p.name == "DTSTART":
+			event.start = p
+		case p.name == "DTEND":
+			event.end = p
+		case p.name == "RRULE":
+			event.rrule = p.value
+		}
+	}
+	return c, nil
+}
+
+// unfold reads the lines of an iCalendar file joining the lines that start with a white space to the
+// previous one
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseProperty splits a content line in name, parameters and value
+func parseProperty(line string) (*icsProperty, error) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return nil, errors.New(fmt.Sprintf("Invalid line '%s'", line))
+	}
+	parts := strings.Split(line[:colon], ";")
+	p := &icsProperty{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) == 2 {
+			p.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+		}
+	}
+	return p, nil
+}
+
+// parseTime parses the value of DTSTART or DTEND, it returns true if the value is a date without time
+// and true if the value is floating, i.e. it is a wall clock without a time zone
+func parseTime(p *icsProperty) (time.Time, bool, bool, error) {
+	if p.params["VALUE"] == "DATE" || len(p.value) == len(icsDate) {
+		t, err := time.Parse(icsDate, p.value)
+		return t, true, true, err
+	}
+	if strings.HasSuffix(p.value, "Z") {
+		t, err := time.Parse(icsDateTimeUTC, p.value)
+		return t, false, false, err
+	}
+	if tzid, ok := p.params["TZID"]; ok {
+		loc, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, false, err
+		}
+		t, err := time.ParseInLocation(icsDateTime, p.value, loc)
+		return t, false, false, err
+	}
+	t, err := time.Parse(icsDateTime, p.value)
+	return t, false, true, err
+}
+
+// addEvent adds the time of the event to the calendar
+func (c *Calendar) addEvent(e *icsEvent) error {
+	if e.start == nil {
+		return errors.New("VEVENT without DTSTART")
+	}
+	start, allDay, floating, err := parseTime(e.start)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Invalid DTSTART '%s'", e.start.value))
+	}
+	end := start.Add(time.Minute)
+	if allDay {
+		end = start.AddDate(0, 0, 1)
+	}
+	if e.end != nil {
+		end, _, _, err = parseTime(e.end)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Invalid DTEND '%s'", e.end.value))
+		}
+	}
+	if !end.After(start) {
+		return errors.New(fmt.Sprintf("DTEND '%s' is not after DTSTART '%s'", e.end.value, e.start.value))
+	}
+
+	if e.rrule != "" {
+		if !allDay {
+			return errors.New(fmt.Sprintf("RRULE '%s' is supported only for all-day events", e.rrule))
+		}
+		return c.addYearly(start, int(end.Sub(start).Hours()/24), e.rrule)
+	}
+	if floating {
+		// DTEND is excluded while the ranges of the calendar include the end
+		c.AddRange(start, end.Add(-time.Minute))
+		return nil
+	}
+	c.AddInterval(start, end)
+	return nil
+}
+
+// addYearly adds an all-day event of the given number of days that repeats every year
+func (c *Calendar) addYearly(start time.Time, days int, rrule string) error {
+	y := yearly{month: start.Month(), day: start.Day(), days: days, from: start.Year(), interval: 1}
+	count := 0
+	for _, part := range strings.Split(rrule, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return errors.New(fmt.Sprintf("Invalid RRULE '%s'", rrule))
+		}
+		var err error
+		switch strings.ToUpper(kv[0]) {
+		case "FREQ":
+			if strings.ToUpper(kv[1]) != "YEARLY" {
+				return errors.New(fmt.Sprintf("Unsupported RRULE '%s', only FREQ=YEARLY is supported", rrule))
+			}
+		case "INTERVAL":
+			y.interval, err = strconv.Atoi(kv[1])
+		case "COUNT":
+			count, err = strconv.Atoi(kv[1])
+			if err == nil && count < 1 {
+				err = errors.New("the count must be positive")
+			}
+		case "UNTIL":
+			// UNTIL can be a date or a date-time, only the date is used as the events last whole days
+			y.until, err = time.Parse(icsDate, strings.SplitN(kv[1], "T", 2)[0])
+		case "BYMONTH", "BYMONTHDAY":
+			// the calendar applications repeat the month and the day of DTSTART, e.g. BYMONTH=12;BYMONTHDAY=25
+			var v int
+			v, err = strconv.Atoi(kv[1])
+			if err == nil && (strings.ToUpper(kv[0]) == "BYMONTH" && time.Month(v) != y.month ||
+				strings.ToUpper(kv[0]) == "BYMONTHDAY" && v != y.day) {
+				return errors.New(fmt.Sprintf("Unsupported RRULE '%s', %s must be the one of DTSTART", rrule, kv[0]))
+			}
+		default:
+			return errors.New(fmt.Sprintf("Unsupported RRULE '%s'", rrule))
+		}
+		if err != nil || y.interval < 1 {
+			return errors.New(fmt.Sprintf("Invalid RRULE '%s'", rrule))
+		}
+	}
+	if count > 0 && !y.until.IsZero() {
+		return errors.New(fmt.Sprintf("Invalid RRULE '%s', COUNT and UNTIL cannot be used together", rrule))
+	}
+	if count > 0 {
+		// the interval can follow the count in the rule and the years without the day do not count,
+		// e.g. the 29th of February
+		for year := y.from; ; year += y.interval {
+			if y.exists(year) {
+				if count--; count == 0 {
+					y.to = year
+					break
+				}
+			}
+		}
+	}
+	c.rules = append(c.rules, y)
+	return nil
+}
+
+// yearly contains the days of an all-day event that repeats every interval years, from the year from
+// to the year to (included, 0 means forever) and starting not after the date until, if it is set
+type yearly struct {
+	month    time.Month
+	day      int
+	days     int
+	from     int
+	to       int
+	until    time.Time
+	interval int
+}
+
+func (y yearly) contains(t time.Time) bool {
+	// an event can start in the previous year and end in the year of t
+	for year := t.Year() - 1; year <= t.Year(); year++ {
+		if year < y.from || (y.to > 0 && year > y.to) || (year-y.from)%y.interval != 0 {
+			continue
+		}
+		if !y.exists(year) || !y.until.IsZero() && time.Date(year, y.month, y.day, 0, 0, 0, 0, time.UTC).After(y.until) {
+			continue
+		}
+		start := time.Date(year, y.month, y.day, 0, 0, 0, 0, t.Location())
+		end := start.AddDate(0, 0, y.days)
+		if !t.Before(start) && t.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// exists tells if the day of the event exists in the year, the years without the day are skipped
+func (y yearly) exists(year int) bool {
+	return time.Date(year, y.month, y.day, 0, 0, 0, 0, time.UTC).Month() == y.month
+}
+
+// interval contains the instants from start (included) to end (excluded)
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+func (i interval) contains(t time.Time) bool {
+	return !t.Before(i.start) && t.Before(i.end)
+}
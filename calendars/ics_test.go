@@ -0,0 +1,121 @@
+package calendars
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleICS = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Christmas\r\n" +
+	"DTSTART;VALUE=DATE:20211225\r\n" +
+	"DTEND;VALUE=DATE:20211227\r\n" +
+	"RRULE:FREQ=YEARLY\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Company day\r\n" +
+	"DTSTART;VALUE=DATE:20210610\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"SUMMARY:Change freeze with a long\r\n" +
+	"  description\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20211105T180000\r\n" +
+	"DTEND:20211105T200000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART;VALUE=DATE:20200101\r\n" +
+	"RRULE:FREQ=YEARLY;COUNT=2\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestParseICS(t *testing.T) {
+	c, err := ParseICS(strings.NewReader(sampleICS), "holidays.ics")
+	require.Nil(t, err)
+	assert.Equal(t, 4, c.Len())
+
+	tcs := []struct {
+		name     string
+		time     time.Time
+		expected bool
+	}{
+		{"yearly event", time.Date(2030, time.December, 26, 23, 59, 0, 0, time.UTC), true},
+		{"day after the yearly event", time.Date(2030, time.December, 27, 0, 0, 0, 0, time.UTC), false},
+		{"before the first year", time.Date(2020, time.December, 25, 0, 0, 0, 0, time.UTC), false},
+		{"all-day event", time.Date(2021, time.June, 10, 8, 0, 0, 0, time.UTC), true},
+		{"day after the all-day event", time.Date(2021, time.June, 11, 0, 0, 0, 0, time.UTC), false},
+		{"event with time zone", time.Date(2021, time.November, 5, 17, 0, 0, 0, time.UTC), true},
+		{"before the event with time zone", time.Date(2021, time.November, 5, 16, 59, 0, 0, time.UTC), false},
+		{"end is excluded", time.Date(2021, time.November, 5, 20, 0, 0, 0, time.UTC), false},
+		{"last year of count", time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), true},
+		{"after count", time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, c.Contains(tc.time))
+		})
+	}
+}
+
+func TestParseICSInvalid(t *testing.T) {
+	tcs := []struct {
+		name  string
+		input string
+	}{
+		{"missing DTSTART", "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT\n"},
+		{"invalid DTSTART", "BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n"},
+		{"end before start", "BEGIN:VEVENT\nDTSTART:20211105T180000Z\nDTEND:20211105T170000Z\nEND:VEVENT\n"},
+		{"unsupported frequency", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20211105\nRRULE:FREQ=WEEKLY\nEND:VEVENT\n"},
+		{"yearly timed event", "BEGIN:VEVENT\nDTSTART:20211105T180000Z\nRRULE:FREQ=YEARLY\nEND:VEVENT\n"},
+		{"unknown time zone", "BEGIN:VEVENT\nDTSTART;TZID=Nowhere/City:20211105T180000\nEND:VEVENT\n"},
+		{"zero count", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20211105\nRRULE:FREQ=YEARLY;COUNT=0\nEND:VEVENT\n"},
+		{"negative count", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20211105\nRRULE:FREQ=YEARLY;COUNT=-1\nEND:VEVENT\n"},
+		{"other month", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20211105\nRRULE:FREQ=YEARLY;BYMONTH=12\nEND:VEVENT\n"},
+		{"other day of the month", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20211105\nRRULE:FREQ=YEARLY;BYMONTHDAY=6\nEND:VEVENT\n"},
+		{"count and until", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20211105\nRRULE:FREQ=YEARLY;COUNT=2;UNTIL=20301231\nEND:VEVENT\n"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseICS(strings.NewReader(tc.input), "test.ics")
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestParseICSYearly(t *testing.T) {
+	tcs := []struct {
+		name     string
+		start    string
+		rrule    string
+		time     time.Time
+		expected bool
+	}{
+		{"count before interval", "20201225", "FREQ=YEARLY;COUNT=3;INTERVAL=2", time.Date(2024, time.December, 25, 0, 0, 0, 0, time.UTC), true},
+		{"interval before count", "20201225", "FREQ=YEARLY;INTERVAL=2;COUNT=3", time.Date(2024, time.December, 25, 0, 0, 0, 0, time.UTC), true},
+		{"after count before interval", "20201225", "FREQ=YEARLY;COUNT=3;INTERVAL=2", time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC), false},
+		{"after interval before count", "20201225", "FREQ=YEARLY;INTERVAL=2;COUNT=3", time.Date(2026, time.December, 25, 0, 0, 0, 0, time.UTC), false},
+		{"year before until", "20200801", "FREQ=YEARLY;UNTIL=20220601", time.Date(2021, time.August, 1, 0, 0, 0, 0, time.UTC), true},
+		{"after until in the same year", "20200801", "FREQ=YEARLY;UNTIL=20220601", time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC), false},
+		{"until on the day", "20200801", "FREQ=YEARLY;UNTIL=20220801T000000Z", time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC), true},
+		{"month and day of the start", "20201225", "FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=25", time.Date(2023, time.December, 25, 0, 0, 0, 0, time.UTC), true},
+		{"leap day", "20200229", "FREQ=YEARLY", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), true},
+		{"no leap day", "20200229", "FREQ=YEARLY", time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC), false},
+		{"count of leap days", "20200229", "FREQ=YEARLY;COUNT=2", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), true},
+		{"after the count of leap days", "20200229", "FREQ=YEARLY;COUNT=2", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			input := "BEGIN:VEVENT\nDTSTART;VALUE=DATE:" + tc.start + "\nRRULE:" + tc.rrule + "\nEND:VEVENT\n"
+			c, err := ParseICS(strings.NewReader(input), "test.ics")
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, c.Contains(tc.time))
+		})
+	}
+}
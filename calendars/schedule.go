@@ -0,0 +1,64 @@
+package calendars
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+)
+
+// Excluded is a Schedule that does not run when one of its calendars contains the time of the run
+type Excluded struct {
+	Schedule  parsers.Schedule
+	Calendars []*Calendar
+}
+
+// Exclude attaches the calendars to the schedule, the returned schedule skips all the runs that are
+// in at least one of the calendars
+func Exclude(s parsers.Schedule, calendars ...*Calendar) *Excluded {
+	return &Excluded{Schedule: s, Calendars: calendars}
+}
+
+// Next returns the first run after t that is not in the calendars or the zero time if there is none in
+// the next parsers.MaxYears years
+func (e *Excluded) Next(t time.Time) time.Time {
+	return e.difference().Next(t)
+}
+
+// Matches returns true if the schedule runs at t and t is not in the calendars
+func (e *Excluded) Matches(t time.Time) bool {
+	return e.difference().Matches(t)
+}
+
+// String returns the description of the schedule and the names of the calendars
+func (e *Excluded) String() string {
+	names := []string{}
+	for _, c := range e.Calendars {
+		names = append(names, c.Name)
+	}
+	return fmt.Sprintf("%s excluding %s", parsers.Describe(e.Schedule), strings.Join(names, ", "))
+}
+
+// difference returns the schedule without the times in the calendars
+func (e *Excluded) difference() *parsers.Composite {
+	excluded := []parsers.Schedule{}
+	for _, c := range e.Calendars {
+		excluded = append(excluded, contained{c})
+	}
+	return parsers.NewDifference(e.Schedule, excluded...)
+}
+
+// contained is the schedule that matches the times in a calendar, it can only be excluded from another
+// schedule because it has no runs of its own
+type contained struct {
+	*Calendar
+}
+
+func (c contained) Next(t time.Time) time.Time {
+	return time.Time{}
+}
+
+func (c contained) Matches(t time.Time) bool {
+	return c.Contains(t)
+}
@@ -0,0 +1,40 @@
+package calendars
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/internal/testutil"
+	"github.com/reclaro/cep/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExclude(t *testing.T) {
+	c, err := Parse(strings.NewReader("2021-12-24..2021-12-26\n12-31\n"), "holidays")
+	require.Nil(t, err)
+	s := Exclude(testutil.Results(t, "0 9 * * 1-5 cmd"), c)
+
+	from := time.Date(2021, time.December, 23, 12, 0, 0, 0, time.UTC)
+	expected := []time.Time{
+		time.Date(2021, time.December, 27, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, time.December, 28, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, time.December, 29, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, time.December, 30, 9, 0, 0, 0, time.UTC),
+		time.Date(2022, time.January, 3, 9, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, expected, parsers.Upcoming(s, from, 5))
+	assert.False(t, s.Matches(time.Date(2021, time.December, 24, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, s.Matches(expected[0]))
+	assert.Equal(t, "0 9 * * 1-5 excluding holidays", s.String())
+}
+
+func TestExcludeAllRuns(t *testing.T) {
+	c := NewCalendar("always")
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		c.AddWeekly(d, 0, minutesPerDay)
+	}
+	s := Exclude(testutil.Results(t, "0 0 * * * cmd"), c)
+	assert.True(t, s.Next(time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)).IsZero())
+}
@@ -0,0 +1,75 @@
+/*
+Package clock abstracts the time functions used by the scheduler, so that the code that runs at the times of
+cron expressions can be tested without sleeping.
+Real is the clock of the system and Fake is a clock that moves only when it is advanced, it fires its timers
+when their time is reached and it can be inspected to know which timers are waiting.
+*/
+package clock
+
+import "time"
+
+// Clock returns the current time and creates timers
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTimer(d time.Duration) Timer
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// Timer sends the time on its channel when it fires, as time.Timer does
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Real is the clock of the system, it uses the functions of the time package
+type Real struct{}
+
+// New returns the clock of the system
+func New() Clock {
+	return Real{}
+}
+
+// Now returns the current time
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Since returns the time elapsed since t
+func (Real) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// NewTimer creates a timer that fires after d
+func (Real) NewTimer(d time.Duration) Timer {
+	return &realTimer{time.NewTimer(d)}
+}
+
+// After returns a channel that receives the current time after d
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Sleep pauses the current goroutine for d
+func (Real) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// realTimer wraps a time.Timer
+type realTimer struct {
+	t *time.Timer
+}
+
+func (rt *realTimer) C() <-chan time.Time {
+	return rt.t.C
+}
+
+func (rt *realTimer) Stop() bool {
+	return rt.t.Stop()
+}
+
+func (rt *realTimer) Reset(d time.Duration) bool {
+	return rt.t.Reset(d)
+}
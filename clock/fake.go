@@ -0,0 +1,178 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a clock whose time changes only with Advance and Set, the timers fire when the time of the clock
+// reaches their deadline. It is safe to use from more goroutines.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	waiters []waiter
+}
+
+// waiter is a goroutine blocked in BlockUntil
+type waiter struct {
+	n    int
+	done chan struct{}
+}
+
+// NewFake returns a fake clock at the time now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the time of the clock
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since returns the time elapsed since t according to the clock
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// NewTimer creates a timer that fires when the clock is advanced by d, a timer with d not greater than 0
+// fires immediately
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ft := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	f.schedule(ft, d)
+	return ft
+}
+
+// After returns a channel that receives the time of the clock when it is advanced by d
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// Sleep blocks until the clock is advanced by d
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// Advance moves the clock forward by d and it fires the timers whose deadline is reached
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.set(f.now.Add(d))
+}
+
+// Set moves the clock to t and it fires the timers whose deadline is reached, the clock never goes back
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t.After(f.now) {
+		f.set(t)
+	}
+}
+
+// Timers returns the deadlines of the timers that did not fire yet and that are not stopped, sorted
+func (f *Fake) Timers() []time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := []time.Time{}
+	for _, ft := range f.timers {
+		res = append(res, ft.deadline)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Before(res[j]) })
+	return res
+}
+
+// BlockUntil blocks until there are at least n waiting timers, it lets a test wait for a goroutine to
+// create its timer before advancing the clock
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	if len(f.timers) >= n {
+		f.mu.Unlock()
+		return
+	}
+	w := waiter{n: n, done: make(chan struct{})}
+	f.waiters = append(f.waiters, w)
+	f.mu.Unlock()
+	<-w.done
+}
+
+// set moves the clock to now and fires the timers, it must be called holding the lock
+func (f *Fake) set(now time.Time) {
+	f.now = now
+	waiting := f.timers[:0]
+	for _, ft := range f.timers {
+		if ft.deadline.After(now) {
+			waiting = append(waiting, ft)
+			continue
+		}
+		ft.fire(now)
+	}
+	f.timers = waiting
+}
+
+// schedule adds a timer that fires after d, it must be called holding the lock
+func (f *Fake) schedule(ft *fakeTimer, d time.Duration) {
+	ft.deadline = f.now.Add(d)
+	if d <= 0 {
+		ft.fire(f.now)
+		return
+	}
+	f.timers = append(f.timers, ft)
+	waiters := f.waiters[:0]
+	for _, w := range f.waiters {
+		if len(f.timers) >= w.n {
+			close(w.done)
+			continue
+		}
+		waiters = append(waiters, w)
+	}
+	f.waiters = waiters
+}
+
+// remove removes a timer and it reports whether the timer was waiting, it must be called holding the lock
+func (f *Fake) remove(ft *fakeTimer) bool {
+	for i, t := range f.timers {
+		if t == ft {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer is a timer of a fake clock
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (ft *fakeTimer) C() <-chan time.Time {
+	return ft.c
+}
+
+func (ft *fakeTimer) Stop() bool {
+	ft.clock.mu.Lock()
+	defer ft.clock.mu.Unlock()
+	return ft.clock.remove(ft)
+}
+
+func (ft *fakeTimer) Reset(d time.Duration) bool {
+	ft.clock.mu.Lock()
+	defer ft.clock.mu.Unlock()
+	active := ft.clock.remove(ft)
+	ft.clock.schedule(ft, d)
+	return active
+}
+
+// fire sends the time without blocking, as time.Timer the channel holds one value
+func (ft *fakeTimer) fire(now time.Time) {
+	select {
+	case ft.c <- now:
+	default:
+	}
+}
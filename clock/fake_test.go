@@ -0,0 +1,98 @@
+package clock
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeNow(t *testing.T) {
+	f := NewFake(start)
+	assert.Equal(t, start, f.Now())
+	f.Advance(90 * time.Second)
+	assert.Equal(t, start.Add(90*time.Second), f.Now())
+	assert.Equal(t, 90*time.Second, f.Since(start))
+	f.Set(start)
+	assert.Equal(t, start.Add(90*time.Second), f.Now(), "the clock must not go back")
+	f.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), f.Now())
+}
+
+func TestFakeTimer(t *testing.T) {
+	f := NewFake(start)
+	timer := f.NewTimer(time.Minute)
+	assert.Equal(t, []time.Time{start.Add(time.Minute)}, f.Timers())
+
+	f.Advance(59 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("the timer fired before its deadline")
+	default:
+	}
+	f.Advance(2 * time.Second)
+	select {
+	case now := <-timer.C():
+		assert.Equal(t, start.Add(61*time.Second), now)
+	default:
+		t.Fatal("the timer did not fire")
+	}
+	assert.Empty(t, f.Timers())
+	assert.False(t, timer.Stop())
+}
+
+func TestFakeTimerStopReset(t *testing.T) {
+	f := NewFake(start)
+	stopped := f.NewTimer(time.Minute)
+	reset := f.NewTimer(time.Minute)
+	assert.True(t, stopped.Stop())
+	assert.True(t, reset.Reset(time.Hour))
+	assert.Equal(t, []time.Time{start.Add(time.Hour)}, f.Timers())
+
+	f.Advance(time.Minute)
+	assert.Len(t, stopped.C(), 0)
+	assert.Len(t, reset.C(), 0)
+	f.Advance(time.Hour)
+	assert.Len(t, reset.C(), 1)
+}
+
+func TestFakeTimerImmediate(t *testing.T) {
+	f := NewFake(start)
+	assert.Equal(t, start, <-f.After(0))
+	assert.Equal(t, start, <-f.After(-time.Second))
+	assert.Empty(t, f.Timers())
+}
+
+func TestFakeTimersOrder(t *testing.T) {
+	f := NewFake(start)
+	f.NewTimer(time.Hour)
+	f.NewTimer(time.Minute)
+	f.NewTimer(time.Second)
+	assert.Equal(t, []time.Time{start.Add(time.Second), start.Add(time.Minute), start.Add(time.Hour)}, f.Timers())
+}
+
+func TestFakeSleep(t *testing.T) {
+	f := NewFake(start)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		f.Sleep(time.Minute)
+	}()
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+	wg.Wait()
+	assert.Equal(t, start.Add(time.Minute), f.Now())
+}
+
+func TestReal(t *testing.T) {
+	c := New()
+	before := time.Now()
+	assert.False(t, c.Now().Before(before))
+	timer := c.NewTimer(time.Millisecond)
+	<-timer.C()
+	assert.True(t, c.Since(before) >= time.Millisecond)
+}
@@ -0,0 +1,588 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/reclaro/cep/analysis"
+	"github.com/reclaro/cep/calendars"
+	"github.com/reclaro/cep/converters"
+	"github.com/reclaro/cep/crontab"
+	"github.com/reclaro/cep/daemon"
+	"github.com/reclaro/cep/expressions"
+	"github.com/reclaro/cep/parsers"
+	"github.com/reclaro/cep/printers"
+	"github.com/reclaro/cep/state"
+)
+
+// commands maps the name of a sub command to the function that runs it, the function receives the
+// arguments after the name of the command
+var commands = map[string]func([]string) error{
+	"stagger": runStagger,
+	"overlap": runOverlap,
+	"stats":   runStats,
+	"next":    runNext,
+	"rrule":   runRRULE,
+	"dst":     runDST,
+	"tz":      runTZ,
+	"compat":  runCompat,
+	"vixie":   runVixie,
+	"run":     runDaemon,
+	"deps":    runDeps,
+}
+
+// stringList is a flag that can be repeated, every value is appended to the list
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseFlags parses the flags of a sub command, unlike flag.Parse the flags can also follow the positional
+// arguments, e.g. cep dst "30 2 * * * cmd" -tz Europe/Berlin. It returns the positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) []string {
+	positional := []string{}
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// exitOnError prints the error and terminates the program
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+// printConversion prints the expressions and the warnings of a conversion, it returns an error if the
+// conversion has no expression so that the command fails
+func printConversion(c *converters.Conversion) error {
+	printers.NewConversionReport().Print(c)
+	if len(c.Expressions) == 0 {
+		return errors.New("No equivalent expression")
+	}
+	return nil
+}
+
+// runStagger reads a crontab file and prints a patched crontab where the jobs are spread over the day
+func runStagger(args []string) error {
+	fs := flag.NewFlagSet("stagger", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep stagger <crontab file>")
+	}
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	f, err := crontab.Load(args[0])
+	if err != nil {
+		return err
+	}
+	plan, err := analysis.Stagger(f)
+	if err != nil {
+		return err
+	}
+	printers.NewStaggerReport().Print(plan)
+	return nil
+}
+
+// runOverlap checks if the runs of the jobs overlap given their expected duration. The input is either
+// a crontab file, where the jobs can be annotated with the duration and the lock, or a cron expression
+func runOverlap(args []string) error {
+	fs := flag.NewFlagSet("overlap", flag.ExitOnError)
+	duration := fs.Duration("duration", 0, "expected duration of the jobs without a duration annotation")
+	horizon := fs.Duration("horizon", 366*24*time.Hour, "period used to search conflicts between jobs sharing a lock")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep overlap [options] <crontab file | cron expression>")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	var jobs []*analysis.Job
+	if _, err := os.Stat(args[0]); err == nil {
+		f, err := crontab.Load(args[0])
+		if err != nil {
+			return err
+		}
+		jobs, err = analysis.JobsFromCrontab(f, *duration)
+		if err != nil {
+			return err
+		}
+	} else {
+		res, err := crontab.ExpandSchedule(args[0])
+		if err != nil {
+			return err
+		}
+		name := res.Command
+		if name == "" {
+			name = args[0]
+		}
+		jobs = []*analysis.Job{{Name: name, Results: res, Duration: *duration}}
+	}
+	printers.NewOverlapReport().Print(analysis.Overlaps(jobs, time.Now(), *horizon))
+	return nil
+}
+
+// runStats prints the statistics of the gaps between the runs of a cron expression
+func runStats(args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	year := fs.Int("year", time.Now().Year(), "year used to find the longest idle period")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep stats [options] <cron expression>")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	res, err := crontab.ExpandSchedule(args[0])
+	if err != nil {
+		return err
+	}
+	printers.NewStatsReport().Print(analysis.NewStats(res, *year))
+	return nil
+}
+
+/*
+runNext prints the next runs of a cron expression. The expression can be combined with other expressions:
+the result is ((expression or -or expressions) and -and expressions) except -except expressions.
+The runs in the -exclude calendars are skipped
+*/
+func runNext(args []string) error {
+	var or, and, except, exclude stringList
+	fs := flag.NewFlagSet("next", flag.ExitOnError)
+	n := fs.Int("n", 5, "number of runs to print")
+	from := fs.String("from", "", "print the runs after this time in RFC3339 format, default now")
+	until := fs.String("until", "", "print all the runs before this time in RFC3339 format instead of -n runs")
+	tz := fs.String("tz", "Local", "time zone of the runs, e.g. Europe/Berlin")
+	dialect := fs.String("dialect", "vixie", "syntax of the expression, see cep compat for the dialects")
+	format := fs.String("format", "text", "output format: text or ics")
+	duration := fs.Duration("duration", time.Minute, "duration of the events in the ics format")
+	dst := fs.String("dst", "", "DST policy for the runs in a skipped or repeated hour: skip, once, twice or shift")
+	fs.Var(&or, "or", "add the runs of this cron expression, it can be repeated")
+	fs.Var(&and, "and", "keep only the runs of this cron expression, it can be repeated")
+	fs.Var(&except, "except", "remove the runs of this cron expression, it can be repeated")
+	fs.Var(&exclude, "exclude", "skip the runs in this calendar or .ics file, it can be repeated")
+	businessDays := fs.String("business-day", "", "run on these business days of the month, e.g. 3 or -1 for the last one. "+
+		"The business days are the days of the week of the expression that are not in the -exclude calendars")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep next [options] <cron expression>")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	holder, err := newHolder(*dialect, args[0])
+	if err != nil {
+		return err
+	}
+	res, err := results(holder)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	// the robfig specs can set their own time zone, e.g. CRON_TZ=Europe/Rome 0 9 * * *
+	if l, ok := holder.(interface{ Location() *time.Location }); ok && l.Location() != nil && *tz == "Local" {
+		loc = l.Location()
+	}
+	start := time.Now().In(loc)
+	if *from != "" {
+		t, err := time.Parse(time.RFC3339, *from)
+		if err != nil {
+			return err
+		}
+		start = t.In(loc)
+	}
+	var prt printers.RunsPrinter
+	switch *format {
+	case "text":
+		prt = printers.NewNextRuns()
+	case "ics":
+		prt = printers.NewICS(*duration)
+	default:
+		return errors.New(fmt.Sprintf("Unknown format '%s'", *format))
+	}
+
+	cals := []*calendars.Calendar{}
+	for _, path := range exclude {
+		c, err := calendars.Load(path)
+		if err != nil {
+			return err
+		}
+		cals = append(cals, c)
+	}
+	var s parsers.Schedule = res
+	if *dst != "" {
+		policy, err := parsers.ParseDSTPolicy(*dst)
+		if err != nil {
+			return err
+		}
+		s = parsers.NewDSTSchedule(res, policy)
+	}
+	if *businessDays != "" {
+		nth := []int{}
+		for _, v := range strings.Split(*businessDays, ",") {
+			d, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return errors.New(fmt.Sprintf("Invalid business day '%s'", v))
+			}
+			nth = append(nth, d)
+		}
+		s, err = calendars.NewBusinessDay(res, nth, cals...)
+		if err != nil {
+			return err
+		}
+	}
+	if len(or) > 0 {
+		others, err := expandAll(or)
+		if err != nil {
+			return err
+		}
+		s = parsers.NewUnion(append([]parsers.Schedule{s}, others...)...)
+	}
+	if len(and) > 0 {
+		others, err := expandAll(and)
+		if err != nil {
+			return err
+		}
+		s = parsers.NewIntersection(append([]parsers.Schedule{s}, others...)...)
+	}
+	if len(except) > 0 {
+		others, err := expandAll(except)
+		if err != nil {
+			return err
+		}
+		s = parsers.NewDifference(s, others...)
+	}
+	if len(cals) > 0 {
+		s = calendars.Exclude(s, cals...)
+	}
+	runs := parsers.Upcoming(s, start, *n)
+	if *until != "" {
+		end, err := time.Parse(time.RFC3339, *until)
+		if err != nil {
+			return err
+		}
+		runs = parsers.Between(s, start.Add(time.Nanosecond), end)
+	}
+	prt.PrintRuns(s, res.Command, runs)
+	return nil
+}
+
+// runRRULE converts a cron expression to RRULEs or an RRULE to a cron expression
+func runRRULE(args []string) error {
+	fs := flag.NewFlagSet("rrule", flag.ExitOnError)
+	dtstart := fs.String("dtstart", "", "DTSTART of the RRULE in RFC3339 format, default now")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep rrule [options] <cron expression | RRULE>")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	input := args[0]
+	if !strings.HasPrefix(strings.ToUpper(input), "RRULE:") && !strings.HasPrefix(strings.ToUpper(input), "FREQ=") {
+		res, err := crontab.ExpandSchedule(input)
+		if err != nil {
+			return err
+		}
+		for _, r := range converters.ToRRULE(res) {
+			fmt.Println("RRULE:" + r)
+		}
+		return nil
+	}
+
+	start := time.Now()
+	if *dtstart != "" {
+		t, err := time.Parse(time.RFC3339, *dtstart)
+		if err != nil {
+			return err
+		}
+		start = t
+	}
+	c, err := converters.FromRRULE(input, start)
+	if err != nil {
+		return err
+	}
+	return printConversion(c)
+}
+
+// runDST prints the runs of a cron expression affected by the DST transitions of a year
+func runDST(args []string) error {
+	fs := flag.NewFlagSet("dst", flag.ExitOnError)
+	tz := fs.String("tz", "Local", "time zone of the runs, e.g. Europe/Berlin")
+	year := fs.Int("year", time.Now().Year(), "year of the transitions")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep dst [options] <cron expression>")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	res, err := crontab.ExpandSchedule(args[0])
+	if err != nil {
+		return err
+	}
+	printers.NewDSTReport().Print(analysis.DSTReport(res, loc, *year))
+	return nil
+}
+
+// runTZ rewrites a cron expression so that it runs at the same instants in another time zone
+func runTZ(args []string) error {
+	fs := flag.NewFlagSet("tz", flag.ExitOnError)
+	from := fs.String("from", "Local", "time zone of the cron expression, e.g. America/New_York")
+	to := fs.String("to", "UTC", "time zone of the rewritten expressions")
+	year := fs.Int("year", time.Now().Year(), "year used for the daylight saving time")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep tz [options] <cron expression>")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	fromLoc, err := time.LoadLocation(*from)
+	if err != nil {
+		return err
+	}
+	toLoc, err := time.LoadLocation(*to)
+	if err != nil {
+		return err
+	}
+	res, err := crontab.ExpandSchedule(args[0])
+	if err != nil {
+		return err
+	}
+	c := converters.ToTimeZone(res, fromLoc, toLoc, *year)
+	for i := range c.Expressions {
+		if res.Command != "" {
+			c.Expressions[i] += " " + res.Command
+		}
+	}
+	return printConversion(c)
+}
+
+// newHolder returns the expression holder for a registered dialect, e.g. vixie (the default syntax)
+func newHolder(dialect string, input string) (expressions.Holder, error) {
+	d, err := expressions.LookupDialect(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return d.New(input)
+}
+
+// runCompat prints which dialects accept a cron expression and why the others reject it
+func runCompat(args []string) error {
+	fs := flag.NewFlagSet("compat", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep compat <cron expression without command>")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	res := expressions.Compat(args[0], func(h expressions.Holder) error {
+		_, err := results(h)
+		return err
+	})
+	printers.NewCompatReport().Print(res)
+	return nil
+}
+
+// runVixie converts an expression of another dialect to five-field Vixie cron expressions
+func runVixie(args []string) error {
+	fs := flag.NewFlagSet("vixie", flag.ExitOnError)
+	dialect := fs.String("dialect", "quartz", "syntax of the expression, see cep compat for the dialects")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep vixie [options] <cron expression>")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	holder, err := newHolder(*dialect, args[0])
+	if err != nil {
+		return err
+	}
+	res, err := results(holder)
+	if err != nil {
+		return err
+	}
+	c := converters.ToVixie(res)
+	for i := range c.Expressions {
+		if res.Command != "" {
+			c.Expressions[i] += " " + res.Command
+		}
+	}
+	return printConversion(c)
+}
+
+// runDaemon runs the jobs of crontab files until the program receives SIGINT or SIGTERM, then it waits for
+// the running jobs for the grace period
+func runDaemon(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	tz := fs.String("tz", "Local", "time zone of the schedules")
+	grace := fs.Duration("grace", 30*time.Second, "time given to the running jobs to finish when stopping")
+	metricsAddr := fs.String("metrics", "", "address where the Prometheus metrics are served on /metrics, e.g. 127.0.0.1:9100")
+	config := fs.String("config", "", "JSON file with the options of the jobs by name, as the annotations")
+	reload := fs.Duration("reload", 10*time.Second, "interval between the checks of the crontab files for changes, 0 to disable the reload")
+	stateFile := fs.String("state", "", "file where the state of the jobs is kept, e.g. the last runs to catch up the missed runs")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep run [options] <crontab file>...")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	loc, err := time.LoadLocation(*tz)
+	if err != nil {
+		return err
+	}
+	files, err := daemon.LoadFiles(args, *config)
+	if err != nil {
+		return err
+	}
+	d, err := daemon.New(files, loc, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if *stateFile != "" {
+		if d.Store, err = state.NewFile(*stateFile); err != nil {
+			return err
+		}
+	}
+	if err := d.Start(context.Background()); err != nil {
+		return err
+	}
+	if *metricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", d.MetricsHandler())
+		server := &http.Server{Addr: *metricsAddr, Handler: mux}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				exitOnError(err)
+			}
+		}()
+		defer server.Close()
+	}
+	if *reload > 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go d.Watch(ctx, args, *config, *reload)
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	<-signals
+	ctx, cancel := context.WithTimeout(context.Background(), *grace)
+	defer cancel()
+	return d.Stop(ctx)
+}
+
+// runDeps checks the dependencies between the jobs of crontab files and it prints their graph
+func runDeps(args []string) error {
+	fs := flag.NewFlagSet("deps", flag.ExitOnError)
+	dot := fs.Bool("dot", false, "print the graph in the DOT language of Graphviz")
+	config := fs.String("config", "", "JSON file with the options of the jobs by name, as the annotations")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cep deps [options] <crontab file>...")
+		fs.PrintDefaults()
+	}
+	args = parseFlags(fs, args)
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	files, err := daemon.LoadFiles(args, *config)
+	if err != nil {
+		return err
+	}
+	d, err := daemon.New(files, nil, ioutil.Discard)
+	if err != nil {
+		return err
+	}
+	g, err := daemon.Dependencies(d.Jobs())
+	if err != nil {
+		return err
+	}
+	if *dot {
+		printers.NewDependencyDOT().Print(g)
+		return nil
+	}
+	printers.NewDependencyReport().Print(g)
+	return nil
+}
+
+// results expands the expression of a holder with the default parser
+func results(holder expressions.Holder) (*parsers.CronResults, error) {
+	p, err := parsers.NewDefaultParser(holder)
+	if err != nil {
+		return nil, err
+	}
+	return p.Results()
+}
+
+// expandAll expands a list of cron expressions into schedules
+func expandAll(inputs []string) ([]parsers.Schedule, error) {
+	res := []parsers.Schedule{}
+	for _, input := range inputs {
+		r, err := crontab.ExpandSchedule(input)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
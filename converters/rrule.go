@@ -0,0 +1,310 @@
+package converters
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+	"github.com/reclaro/cep/utils"
+)
+
+// rruleDays are the names of the days of the week in the RRULEs, the index is the cron day of the week
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Conversion is the result of the conversion of a schedule from a format to another
+type Conversion struct {
+	// Expressions are the converted schedule, the union of the expressions is equivalent to the input
+	Expressions []string
+	// Lossy is true if the converted schedule does not run exactly at the same times of the input
+	Lossy bool
+	// Warnings describe the differences between the input and the converted schedule
+	Warnings []string
+}
+
+// lose records a difference between the input and the converted schedule
+func (c *Conversion) lose(format string, args ...interface{}) {
+	c.Lossy = true
+	c.Warnings = append(c.Warnings, fmt.Sprintf(format, args...))
+}
+
+/*
+ToRRULE converts the expanded fields of a cron expression to RFC 5545 recurrence rules.
+A single RRULE cannot express that a cron job runs when the day of the month OR the day of the week matches
+(in an RRULE all the BYxxx parts must match), so when both day fields are restricted the result contains
+a rule for the days of the month and a rule for the days of the week. The union of the rules runs exactly
+at the times of the cron expression, their DTSTART must be a run of the expression.
+*/
+func ToRRULE(cr *parsers.CronResults) []string {
+	base := []string{"FREQ=MINUTELY"}
+	if len(cr.Month) < 12 {
+		base = append(base, "BYMONTH="+joinInts(cr.Month))
+	}
+	clock := []string{}
+	if len(cr.Hour) < 24 {
+		clock = append(clock, "BYHOUR="+joinInts(cr.Hour))
+	}
+	if len(cr.Minute) < 60 {
+		clock = append(clock, "BYMINUTE="+joinInts(cr.Minute))
+	}
+	monthDays := "BYMONTHDAY=" + joinInts(cr.DayMonth)
+	days := []string{}
+	for _, d := range cr.DayWeek {
+		days = append(days, rruleDays[d])
+	}
+	weekDays := "BYDAY=" + strings.Join(days, ",")
+
+	rule := func(parts ...string) string {
+		all := append(append([]string{}, base...), parts...)
+		return strings.Join(append(all, clock...), ";")
+	}
+	domRestricted, dowRestricted := len(cr.DayMonth) < 31, len(cr.DayWeek) < 7
+	switch {
+	case domRestricted && dowRestricted:
+		return []string{rule(monthDays), rule(weekDays)}
+	case domRestricted:
+		return []string{rule(monthDays)}
+	case dowRestricted:
+		return []string{rule(weekDays)}
+	}
+	return []string{rule()}
+}
+
+// rruleParts are the names of the parts of an RRULE
+var rruleParts = []string{"FREQ", "UNTIL", "COUNT", "INTERVAL", "BYSECOND", "BYMINUTE", "BYHOUR", "BYDAY",
+	"BYMONTHDAY", "BYYEARDAY", "BYWEEKNO", "BYMONTH", "BYSETPOS", "WKST"}
+
+// rrule contains the parts of an RRULE
+type rrule struct {
+	freq     string
+	interval int
+	parts    map[string]string
+}
+
+// parseRRULE splits an RRULE in its parts, the RRULE: prefix is optional
+func parseRRULE(input string) (*rrule, error) {
+	r := &rrule{interval: 1, parts: map[string]string{}}
+	input = strings.TrimPrefix(strings.TrimSpace(input), "RRULE:")
+	for _, part := range strings.Split(input, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return nil, errors.New(fmt.Sprintf("Invalid RRULE part '%s'", part))
+		}
+		name := strings.ToUpper(kv[0])
+		known := false
+		for _, n := range rruleParts {
+			known = known || n == name
+		}
+		if !known {
+			return nil, errors.New(fmt.Sprintf("Invalid RRULE part '%s'", part))
+		}
+		r.parts[name] = strings.ToUpper(kv[1])
+	}
+	r.freq = r.parts["FREQ"]
+	if i, ok := r.parts["INTERVAL"]; ok {
+		v, err := strconv.Atoi(i)
+		if err != nil || v < 1 {
+			return nil, errors.New(fmt.Sprintf("Invalid INTERVAL '%s'", i))
+		}
+		r.interval = v
+	}
+	return r, nil
+}
+
+/*
+FromRRULE converts an RRULE to the 5 time fields of a cron expression. The DTSTART of the rule is needed
+because the RRULE takes from it the values that are not in the BYxxx parts, e.g. the hour of a daily rule.
+The result is Lossy when the cron expression does not run exactly at the times of the RRULE, for example
+when the rule has a COUNT, an interval that does not divide the period of the field or BYDAY with ordinals.
+An error is returned if the RRULE is not valid or it has a frequency that cron cannot express at all.
+*/
+func FromRRULE(input string, dtstart time.Time) (*Conversion, error) {
+	r, err := parseRRULE(input)
+	if err != nil {
+		return nil, err
+	}
+	c := &Conversion{Warnings: []string{}}
+	fields := map[string]string{
+		"minute": strconv.Itoa(dtstart.Minute()),
+		"hour":   strconv.Itoa(dtstart.Hour()),
+		"dom":    strconv.Itoa(dtstart.Day()),
+		"month":  strconv.Itoa(int(dtstart.Month())),
+		"dow":    "*",
+	}
+	switch r.freq {
+	case "MINUTELY":
+		fields["minute"] = c.step(dtstart.Minute(), r.interval, []int{0, 59}, "minutes")
+		fields["hour"], fields["dom"], fields["month"] = "*", "*", "*"
+	case "HOURLY":
+		fields["hour"] = c.step(dtstart.Hour(), r.interval, []int{0, 23}, "hours")
+		fields["dom"], fields["month"] = "*", "*"
+	case "DAILY":
+		fields["dom"], fields["month"] = "*", "*"
+		if r.interval > 1 {
+			c.lose("every %d days cannot be expressed, the expression runs every day", r.interval)
+		}
+	case "WEEKLY":
+		fields["dom"], fields["month"] = "*", "*"
+		fields["dow"] = strconv.Itoa(int(dtstart.Weekday()))
+		if r.interval > 1 {
+			c.lose("every %d weeks cannot be expressed, the expression runs every week", r.interval)
+		}
+	case "MONTHLY":
+		fields["month"] = c.step(int(dtstart.Month()), r.interval, []int{1, 12}, "months")
+	case "YEARLY":
+		if r.interval > 1 {
+			c.lose("every %d years cannot be expressed, the expression runs every year", r.interval)
+		}
+		// without BYMONTH the days of the month and of the week are in every month of the year
+		_, byMonthDay := r.parts["BYMONTHDAY"]
+		_, byDay := r.parts["BYDAY"]
+		if byMonthDay || byDay {
+			fields["month"] = "*"
+		}
+	case "SECONDLY":
+		return nil, errors.New("FREQ=SECONDLY cannot be expressed by a cron expression")
+	default:
+		return nil, errors.New(fmt.Sprintf("Invalid FREQ '%s'", r.freq))
+	}
+
+	if err := c.applyParts(r, fields); err != nil {
+		return nil, err
+	}
+	c.Expressions = []string{strings.Join([]string{fields["minute"], fields["hour"], fields["dom"], fields["month"], fields["dow"]}, " ")}
+	return c, nil
+}
+
+// applyParts overrides the fields with the BYxxx parts of the rule
+func (c *Conversion) applyParts(r *rrule, fields map[string]string) error {
+	for _, p := range []struct {
+		name    string
+		field   string
+		allowed []int
+	}{
+		{"BYMINUTE", "minute", []int{0, 59}},
+		{"BYHOUR", "hour", []int{0, 23}},
+		{"BYMONTH", "month", []int{1, 12}},
+	} {
+		if v, ok := r.parts[p.name]; ok {
+			values, err := parseInts(v, p.allowed)
+			if err != nil {
+				return errors.New(fmt.Sprintf("Invalid %s '%s'", p.name, v))
+			}
+			fields[p.field] = utils.CompactValues(values, p.allowed)
+		}
+	}
+
+	_, byMonthDay := r.parts["BYMONTHDAY"]
+	_, byDay := r.parts["BYDAY"]
+	if v, ok := r.parts["BYMONTHDAY"]; ok {
+		values, err := parseInts(v, []int{-31, 31})
+		if err != nil || contains(values, 0) {
+			return errors.New(fmt.Sprintf("Invalid BYMONTHDAY '%s'", v))
+		}
+		positive := []int{}
+		for _, d := range values {
+			if d > 0 {
+				positive = append(positive, d)
+			} else if d < 0 {
+				c.lose("BYMONTHDAY %d counts from the end of the month, it is dropped", d)
+			}
+		}
+		if len(positive) == 0 {
+			return errors.New(fmt.Sprintf("BYMONTHDAY '%s' cannot be expressed", v))
+		}
+		fields["dom"] = utils.CompactValues(positive, []int{1, 31})
+	}
+	if v, ok := r.parts["BYDAY"]; ok {
+		days := []int{}
+		for _, d := range strings.Split(v, ",") {
+			name := strings.TrimLeft(d, "+-0123456789")
+			if name != d {
+				c.lose("BYDAY %s selects a single occurrence in the period, the expression runs every %s", d, name)
+			}
+			index := -1
+			for i, n := range rruleDays {
+				if n == name {
+					index = i
+				}
+			}
+			if index < 0 {
+				return errors.New(fmt.Sprintf("Invalid BYDAY '%s'", v))
+			}
+			days = append(days, index)
+		}
+		fields["dow"] = utils.CompactValues(utils.SortedUniqueInts(days), []int{0, 6})
+		if !byMonthDay {
+			fields["dom"] = "*"
+		}
+	}
+	if byMonthDay && byDay {
+		c.lose("BYMONTHDAY and BYDAY must both match in an RRULE, the expression runs when either matches")
+	}
+
+	for _, name := range []string{"BYSETPOS", "BYYEARDAY", "BYWEEKNO", "WKST"} {
+		if v, ok := r.parts[name]; ok {
+			c.lose("%s=%s cannot be expressed, it is dropped", name, v)
+		}
+	}
+	if v, ok := r.parts["BYSECOND"]; ok && v != "0" {
+		c.lose("BYSECOND=%s cannot be expressed, the expression runs at second 0", v)
+	}
+	if v, ok := r.parts["COUNT"]; ok {
+		c.lose("COUNT=%s cannot be expressed, the expression runs forever", v)
+	}
+	if v, ok := r.parts["UNTIL"]; ok {
+		c.lose("UNTIL=%s cannot be expressed, the expression runs forever", v)
+	}
+	return nil
+}
+
+// step returns the field for a rule that runs every interval units starting from start. If the interval
+// does not divide the number of allowed values the rule cannot be expressed exactly
+func (c *Conversion) step(start int, interval int, allowedValues []int, unit string) string {
+	if interval == 1 {
+		return "*"
+	}
+	size := allowedValues[1] - allowedValues[0] + 1
+	if size%interval != 0 {
+		c.lose("every %d %s restarts from %d at the end of the period", interval, unit, allowedValues[0])
+	}
+	values := []int{}
+	for v := allowedValues[0] + (start-allowedValues[0])%interval; v <= allowedValues[1]; v += interval {
+		values = append(values, v)
+	}
+	return utils.CompactValues(values, allowedValues)
+}
+
+// contains returns true if the value is in the input
+func contains(input []int, value int) bool {
+	for _, v := range input {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// joinInts joins the values with a comma
+func joinInts(values []int) string {
+	s := []string{}
+	for _, v := range values {
+		s = append(s, strconv.Itoa(v))
+	}
+	return strings.Join(s, ",")
+}
+
+// parseInts parses a list of int separated by a comma and it checks they are in the allowed values
+func parseInts(input string, allowedValues []int) ([]int, error) {
+	res := []int{}
+	for _, s := range strings.Split(input, ",") {
+		v, err := strconv.Atoi(strings.TrimPrefix(s, "+"))
+		if err != nil || v < allowedValues[0] || v > allowedValues[1] {
+			return nil, errors.New(fmt.Sprintf("Invalid value '%s'", s))
+		}
+		res = append(res, v)
+	}
+	return utils.SortedUniqueInts(res), nil
+}
@@ -0,0 +1,95 @@
+package converters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToRRULE(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"every minute", "* * * * * cmd", []string{"FREQ=MINUTELY"}},
+		{"every 15 minutes", "*/15 * * * * cmd", []string{"FREQ=MINUTELY;BYMINUTE=0,15,30,45"}},
+		{"weekdays", "30 9 * 1-3 MON-FRI cmd", []string{"FREQ=MINUTELY;BYMONTH=1,2,3;BYDAY=MO,TU,WE,TH,FR;BYHOUR=9;BYMINUTE=30"}},
+		{"days of the month", "0 0 1,15 * * cmd", []string{"FREQ=MINUTELY;BYMONTHDAY=1,15;BYHOUR=0;BYMINUTE=0"}},
+		{"both day fields", "0 0 1 * 0 cmd", []string{
+			"FREQ=MINUTELY;BYMONTHDAY=1;BYHOUR=0;BYMINUTE=0",
+			"FREQ=MINUTELY;BYDAY=SU;BYHOUR=0;BYMINUTE=0",
+		}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ToRRULE(testutil.Results(t, tc.input)))
+		})
+	}
+}
+
+func TestFromRRULE(t *testing.T) {
+	// Monday 1st of March 2021 at 09:30
+	dtstart := time.Date(2021, time.March, 1, 9, 30, 0, 0, time.UTC)
+	tcs := []struct {
+		name     string
+		input    string
+		expected string
+		lossy    bool
+	}{
+		{"minutely", "FREQ=MINUTELY;BYMINUTE=0,15,30,45;BYHOUR=9", "*/15 9 * * *", false},
+		{"minutely with interval", "RRULE:FREQ=MINUTELY;INTERVAL=20", "10/20 * * * *", false},
+		{"minutely with interval not dividing the hour", "FREQ=MINUTELY;INTERVAL=7", "2/7 * * * *", true},
+		{"hourly", "FREQ=HOURLY;INTERVAL=6", "30 3/6 * * *", false},
+		{"daily", "FREQ=DAILY;BYHOUR=8,20;BYMINUTE=0", "0 8,20 * * *", false},
+		{"every other day", "FREQ=DAILY;INTERVAL=2", "30 9 * * *", true},
+		{"weekly from dtstart", "FREQ=WEEKLY", "30 9 * * 1", false},
+		{"weekly", "FREQ=WEEKLY;BYDAY=MO,WE,FR", "30 9 * * 1/2", false},
+		{"monthly", "FREQ=MONTHLY;BYMONTHDAY=1,15", "30 9 1,15 * *", false},
+		{"quarterly", "FREQ=MONTHLY;INTERVAL=3", "30 9 1 3/3 *", false},
+		{"first monday", "FREQ=MONTHLY;BYDAY=1MO", "30 9 * * 1", true},
+		{"last day of the month", "FREQ=MONTHLY;BYMONTHDAY=1,-1", "30 9 1 * *", true},
+		{"yearly", "FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=25", "30 9 25 12 *", false},
+		{"yearly from dtstart", "FREQ=YEARLY", "30 9 1 3 *", false},
+		{"yearly by day of the month", "FREQ=YEARLY;BYMONTHDAY=1", "30 9 1 * *", false},
+		{"yearly by day of the week", "FREQ=YEARLY;BYDAY=MO", "30 9 * * 1", false},
+		{"yearly by day of the week in a month", "FREQ=YEARLY;BYMONTH=6;BYDAY=SU", "30 9 * 6 0", false},
+		{"week start", "FREQ=WEEKLY;WKST=SU", "30 9 * * 1", true},
+		{"count", "FREQ=DAILY;COUNT=10", "30 9 * * *", true},
+		{"day of month and day of week", "FREQ=MONTHLY;BYMONTHDAY=13;BYDAY=FR", "30 9 13 * 5", true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := FromRRULE(tc.input, dtstart)
+			require.Nil(t, err)
+			assert.Equal(t, []string{tc.expected}, c.Expressions)
+			assert.Equal(t, tc.lossy, c.Lossy)
+			assert.Equal(t, tc.lossy, len(c.Warnings) > 0)
+		})
+	}
+}
+
+func TestFromRRULEInvalid(t *testing.T) {
+	dtstart := time.Date(2021, time.March, 1, 9, 30, 0, 0, time.UTC)
+	for _, input := range []string{"FREQ=SECONDLY", "FREQ=FORTNIGHTLY", "FREQ=DAILY;BYHOUR=25", "FREQ=DAILY;INTERVAL=0", "FREQ", "FREQ=MONTHLY;BYMONTHDAY=-1", "FREQ=DAILY;FOO=1"} {
+		t.Run(input, func(t *testing.T) {
+			_, err := FromRRULE(input, dtstart)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	dtstart := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	rules := ToRRULE(testutil.Results(t, "*/10 8-18 * 1-6 1-5 cmd"))
+	require.Len(t, rules, 1)
+	c, err := FromRRULE(rules[0], dtstart)
+	require.Nil(t, err)
+	assert.False(t, c.Lossy)
+	assert.Equal(t, []string{"*/10 8-18 * 1-6 1-5"}, c.Expressions)
+}
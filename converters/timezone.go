@@ -0,0 +1,201 @@
+package converters
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+	"github.com/reclaro/cep/utils"
+)
+
+// minutesPerDay is the number of minutes in a day
+const minutesPerDay = 24 * 60
+
+/*
+ToTimeZone rewrites a cron expression written for the location from so that it runs at the same instants
+when it is executed in the location to.
+The difference between the offsets of the two locations can change during the year because of the
+daylight saving time, the difference of every month is the one of the majority of its days in the given
+year. The months with the same difference share the same expressions, a warning lists the days of a month
+that have a different difference because their runs are not at the same instants.
+When a run moves to the previous or the next day its day fields are moved as well: the days of the week are
+always exact, the days of the month are not when the run crosses the end of a month, in that case
+a warning describes the difference.
+The time fields of an expression must be a product of a set of minutes and a set of hours, so the rewrite
+can be split in more expressions.
+*/
+func ToTimeZone(cr *parsers.CronResults, from *time.Location, to *time.Location, year int) *Conversion {
+	c := &Conversion{Expressions: []string{}, Warnings: []string{}}
+
+	// the difference in minutes of every month and the months with the same difference
+	months := map[int][]int{}
+	diffs := []int{}
+	for _, m := range cr.Month {
+		diff := c.monthDifference(year, time.Month(m), from, to)
+		if _, ok := months[diff]; !ok {
+			diffs = append(diffs, diff)
+		}
+		months[diff] = append(months[diff], m)
+	}
+	sort.Ints(diffs)
+	for _, diff := range diffs {
+		c.shift(cr, months[diff], diff)
+	}
+	return c
+}
+
+// monthDifference returns the difference in minutes between the offsets of the locations for the majority
+// of the days of the month, the days with a different difference are recorded as warnings
+func (c *Conversion) monthDifference(year int, month time.Month, from *time.Location, to *time.Location) int {
+	days := map[int][]int{}
+	for d := time.Date(year, month, 1, 12, 0, 0, 0, from); d.Month() == month; d = d.AddDate(0, 0, 1) {
+		_, fromOffset := d.Zone()
+		_, toOffset := d.In(to).Zone()
+		diff := (toOffset - fromOffset) / 60
+		days[diff] = append(days[diff], d.Day())
+	}
+	best := 0
+	for diff, ds := range days {
+		if len(ds) > len(days[best]) || (len(ds) == len(days[best]) && diff < best) {
+			best = diff
+		}
+	}
+	for diff, ds := range days {
+		if diff != best {
+			c.lose("from %d-%02d-%02d to %d-%02d-%02d the runs are off by %s because of the daylight saving time",
+				year, month, ds[0], year, month, ds[len(ds)-1], time.Duration(diff-best)*time.Minute)
+		}
+	}
+	return best
+}
+
+// shift adds the expressions for the months of the cron expression moved by diff minutes
+func (c *Conversion) shift(cr *parsers.CronResults, months []int, diff int) {
+	// the target minutes of the day for every day shift (-1, 0 or +1)
+	groups := map[int][]int{}
+	for _, h := range cr.Hour {
+		for _, m := range cr.Minute {
+			t := h*60 + m + diff
+			dayShift := 0
+			for t < 0 {
+				t += minutesPerDay
+				dayShift--
+			}
+			for t >= minutesPerDay {
+				t -= minutesPerDay
+				dayShift++
+			}
+			groups[dayShift] = append(groups[dayShift], t)
+		}
+	}
+	shifts := []int{}
+	for s := range groups {
+		shifts = append(shifts, s)
+	}
+	sort.Ints(shifts)
+	for _, s := range shifts {
+		dom, month, dow, ok := c.shiftDays(cr, months, s)
+		if !ok {
+			continue
+		}
+		for _, clock := range productFields(groups[s]) {
+			c.Expressions = append(c.Expressions, strings.Join([]string{clock[0], clock[1], dom, month, dow}, " "))
+		}
+	}
+}
+
+// shiftDays returns the day fields of the cron expression for the runs moved by dayShift days, it returns
+// false if no day is left after the shift. When both day fields are restricted and no day of the month is
+// left, only the days of the week are returned
+func (c *Conversion) shiftDays(cr *parsers.CronResults, months []int, dayShift int) (string, string, string, bool) {
+	month := utils.CompactValues(months, []int{1, 12})
+	domAll, dowAll := len(cr.DayMonth) == 31, len(cr.DayWeek) == 7
+	if dayShift == 0 {
+		return utils.CompactValues(cr.DayMonth, []int{1, 31}), month, utils.CompactValues(cr.DayWeek, []int{0, 6}), true
+	}
+
+	dow := []int{}
+	for _, d := range cr.DayWeek {
+		dow = append(dow, ((d+dayShift)%7+7)%7)
+	}
+	dom := []int{}
+	for _, d := range cr.DayMonth {
+		shifted := d + dayShift
+		if shifted < 1 || shifted > 31 {
+			if !domAll {
+				c.lose("the runs on day %d of the month move to another month, they are dropped", d)
+			}
+			continue
+		}
+		dom = append(dom, shifted)
+	}
+	if domAll {
+		dom = cr.DayMonth
+		if len(months) < 12 && dayShift > 0 {
+			c.lose("the runs on the last day of a month move to the next month, the months are not moved")
+		} else if len(months) < 12 {
+			c.lose("the runs on the first day of a month move to the previous month, the months are not moved")
+		}
+	} else if len(dom) > 0 && dayShift > 0 {
+		c.lose("the days of the month are moved by %d, the runs at the end of the months shorter than 31 days are not moved to the next month", dayShift)
+	} else if len(dom) > 0 {
+		c.lose("the days of the month are moved by %d, the runs on the 1st of the month are not moved to the previous month", dayShift)
+	}
+	if len(dom) == 0 && !dowAll {
+		// both fields are restricted, the runs on the days of the week are still there
+		return "*", month, utils.CompactValues(utils.SortedUniqueInts(dow), []int{0, 6}), true
+	}
+	if len(dom) == 0 {
+		return "", "", "", false
+	}
+	domField := utils.CompactValues(utils.SortedUniqueInts(dom), []int{1, 31})
+	if dowAll {
+		return domField, month, "*", true
+	}
+	return domField, month, utils.CompactValues(utils.SortedUniqueInts(dow), []int{0, 6}), true
+}
+
+// productFields splits the minutes of the day in groups that can be expressed by a Minute and an Hour
+// field. The minutes are grouped either by the hours with the same minutes or by the minutes with the same
+// hours, whichever needs less groups. It returns the minute and hour fields of every group
+func productFields(minutes []int) [][2]string {
+	byHour := map[int][]int{}
+	byMinute := map[int][]int{}
+	for _, m := range minutes {
+		byHour[m/60] = append(byHour[m/60], m%60)
+		byMinute[m%60] = append(byMinute[m%60], m/60)
+	}
+	rows := groupFields(byHour, 24, []int{0, 59}, []int{0, 23})
+	columns := groupFields(byMinute, 60, []int{0, 23}, []int{0, 59})
+	if len(columns) < len(rows) {
+		for i := range columns {
+			columns[i][0], columns[i][1] = columns[i][1], columns[i][0]
+		}
+		return columns
+	}
+	return rows
+}
+
+// groupFields groups the keys with the same values. It returns the field of the values and the field of the
+// keys of every group, in the order of the smallest key of the group
+func groupFields(values map[int][]int, size int, allowedValues []int, allowedKeys []int) [][2]string {
+	keysByValues := map[string][]int{}
+	fields := []string{}
+	for k := 0; k < size; k++ {
+		v, ok := values[k]
+		if !ok {
+			continue
+		}
+		field := utils.CompactValues(utils.SortedUniqueInts(v), allowedValues)
+		if _, ok := keysByValues[field]; !ok {
+			fields = append(fields, field)
+		}
+		keysByValues[field] = append(keysByValues[field], k)
+	}
+	res := [][2]string{}
+	for _, f := range fields {
+		res = append(res, [2]string{f, utils.CompactValues(keysByValues[f], allowedKeys)})
+	}
+	return res
+}
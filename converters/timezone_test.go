@@ -0,0 +1,66 @@
+package converters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func location(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	require.Nil(t, err)
+	return loc
+}
+
+func TestToTimeZone(t *testing.T) {
+	newYork := location(t, "America/New_York")
+	c := ToTimeZone(testutil.Results(t, "30 9 * * 1-5 cmd"), newYork, time.UTC, 2021)
+
+	// EDT (UTC-4) from March to October, EST (UTC-5) in the other months
+	assert.Equal(t, []string{"30 13 * 3-10 1-5", "30 14 * 1,2,11,12 1-5"}, c.Expressions)
+	assert.True(t, c.Lossy)
+	// in 2021 the daylight saving time starts the 14th of March and it ends the 7th of November
+	assert.Contains(t, c.Warnings, "from 2021-03-01 to 2021-03-13 the runs are off by 1h0m0s because of the daylight saving time")
+	assert.Contains(t, c.Warnings, "from 2021-11-01 to 2021-11-06 the runs are off by -1h0m0s because of the daylight saving time")
+}
+
+func TestToTimeZoneNextDay(t *testing.T) {
+	newYork := location(t, "America/New_York")
+	// 22:00 and 23:30 in New York in January are 03:00 and 04:30 in UTC of the next day
+	c := ToTimeZone(testutil.Results(t, "0,30 12,22-23 * 1 1-5 cmd"), newYork, time.UTC, 2021)
+	assert.Equal(t, []string{"0,30 17 * 1 1-5", "0,30 3,4 * 1 2-6"}, c.Expressions)
+	assert.Contains(t, c.Warnings, "the runs on the last day of a month move to the next month, the months are not moved")
+}
+
+func TestToTimeZoneSameOffset(t *testing.T) {
+	c := ToTimeZone(testutil.Results(t, "*/15 1 1,15 * * cmd"), time.UTC, time.FixedZone("UTC", 0), 2021)
+	assert.Equal(t, []string{"*/15 1 1,15 * *"}, c.Expressions)
+	assert.False(t, c.Lossy)
+}
+
+func TestToTimeZoneSplitHours(t *testing.T) {
+	// 30 minutes of difference, 10:45 and 11:45 become 11:15 and 12:15 while 10:15 becomes 10:45
+	c := ToTimeZone(testutil.Results(t, "15,45 10,11 * * * cmd"), time.UTC, time.FixedZone("X", 30*60), 2021)
+	assert.Equal(t, []string{"15 11,12 * * *", "45 10,11 * * *"}, c.Expressions)
+	assert.False(t, c.Lossy)
+}
+
+func TestToTimeZonePreviousDay(t *testing.T) {
+	// 01:00 in Tokyo is 16:00 UTC of the previous day
+	tokyo := location(t, "Asia/Tokyo")
+	c := ToTimeZone(testutil.Results(t, "0 1 1,15 * * cmd"), tokyo, time.UTC, 2021)
+	assert.Equal(t, []string{"0 16 14 * *"}, c.Expressions)
+	assert.True(t, c.Lossy)
+}
+
+func TestToTimeZoneDaysOfTheWeekLeft(t *testing.T) {
+	// the 1st of January in Tokyo is the 31st of December in UTC, the Mondays are still Sundays
+	tokyo := location(t, "Asia/Tokyo")
+	c := ToTimeZone(testutil.Results(t, "0 1 1 * 1 cmd"), tokyo, time.UTC, 2021)
+	assert.Equal(t, []string{"0 16 * * 0"}, c.Expressions)
+	assert.True(t, c.Lossy)
+	assert.Contains(t, c.Warnings, "the runs on day 1 of the month move to another month, they are dropped")
+}
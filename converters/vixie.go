@@ -0,0 +1,118 @@
+package converters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/reclaro/cep/parsers"
+	"github.com/reclaro/cep/utils"
+)
+
+// monthLengths groups the months by their number of days, February has 28 days as in the years that
+// are not leap years
+var monthLengths = []struct {
+	days   int
+	months []int
+}{
+	{31, []int{1, 3, 5, 7, 8, 10, 12}},
+	{30, []int{4, 6, 9, 11}},
+	{28, []int{2}},
+}
+
+/*
+ToVixie converts an expression of the extended model, e.g. a Quartz or Spring expression, to five-field
+Vixie cron expressions without a command.
+The seconds are dropped only when the expression runs at second 0 and the last day of the month (L and L-n)
+becomes an expression for each length of the months. The years are dropped with a warning because the
+converted expressions run every year.
+The constructs that cannot be written in Vixie cron, such as the seconds, the last or the nth day of the
+week of the month (dL and d#n), the weekdays (LW and nW), the constant intervals and a day that must match
+both the day of the month and the day of the week, are reported in the warnings and the result does not
+have any expression.
+*/
+func ToVixie(cr *parsers.CronResults) *Conversion {
+	c := &Conversion{}
+	if cr.Every > 0 {
+		c.lose("the constant interval of %s has no equivalent", cr.Every)
+		return c
+	}
+	if len(cr.Second) > 1 || (len(cr.Second) == 1 && cr.Second[0] != 0) {
+		c.lose("the seconds %s have no equivalent, Vixie cron runs at second 0", utils.CompactValues(cr.Second, []int{0, 59}))
+	}
+	for _, r := range cr.DayWeekRules {
+		c.lose("%s has no equivalent", ruleText(r))
+	}
+	for _, r := range cr.DayMonthRules {
+		if r.Kind == parsers.LastWeekday || r.Kind == parsers.NearestWeekday {
+			c.lose("%s has no equivalent", ruleText(r))
+		}
+	}
+	domRestricted := len(cr.DayMonth) < 31 || len(cr.DayMonthRules) > 0
+	dowRestricted := len(cr.DayWeek) < 7 || len(cr.DayWeekRules) > 0
+	if cr.MatchBothDays && domRestricted && dowRestricted {
+		c.lose("a day that matches both the day of the month and the day of the week has no equivalent, " +
+			"Vixie cron runs when one of them matches")
+	}
+	if c.Lossy {
+		return c
+	}
+
+	if len(cr.Year) > 0 {
+		c.lose("the years %s are dropped, the expressions run every year", utils.CompactValues(cr.Year, []int{1970, 2199}))
+	}
+	minute := utils.CompactValues(cr.Minute, []int{0, 59})
+	hour := utils.CompactValues(cr.Hour, []int{0, 23})
+	dow := utils.CompactValues(cr.DayWeek, []int{0, 6})
+	if len(cr.DayMonthRules) == 0 {
+		c.Expressions = append(c.Expressions, strings.Join([]string{minute, hour,
+			utils.CompactValues(cr.DayMonth, []int{1, 31}), utils.CompactValues(cr.Month, []int{1, 12}), dow}, " "))
+		return c
+	}
+
+	// the last day of the month, or a day before it, is a different day for each length of the months
+	offset := cr.DayMonthRules[0].Offset
+	for _, l := range monthLengths {
+		months := []int{}
+		for _, m := range l.months {
+			if contains(cr.Month, m) {
+				months = append(months, m)
+			}
+		}
+		day := l.days - offset
+		if len(months) == 0 || day < 1 {
+			continue
+		}
+		c.Expressions = append(c.Expressions, strings.Join([]string{minute, hour, fmt.Sprint(day),
+			utils.CompactValues(months, []int{1, 12}), dow}, " "))
+		if l.days == 28 {
+			c.lose("in the leap years February has 29 days, the expression runs on February %d instead of February %d",
+				day, day+1)
+		}
+	}
+	return c
+}
+
+// ruleText describes a day rule
+func ruleText(r parsers.DayRule) string {
+	switch r.Kind {
+	case parsers.LastDay:
+		if r.Offset > 0 {
+			return fmt.Sprintf("the day %d days before the last day of the month", r.Offset)
+		}
+		return "the last day of the month"
+	case parsers.LastWeekday:
+		return "the last weekday of the month"
+	case parsers.NearestWeekday:
+		return fmt.Sprintf("the weekday nearest to the day %d of the month", r.Day)
+	case parsers.LastDayOfWeek:
+		return fmt.Sprintf("the last %s of the month", r.Weekday)
+	case parsers.NthDayOfWeek:
+		return fmt.Sprintf("the %s %s of the month", ordinal(r.Nth), r.Weekday)
+	}
+	return r.String()
+}
+
+// ordinal returns the ordinal of n from 1 to 5
+func ordinal(n int) string {
+	return []string{"", "first", "second", "third", "fourth", "fifth"}[n]
+}
@@ -0,0 +1,72 @@
+package converters
+
+import (
+	"testing"
+
+	"github.com/reclaro/cep/expressions"
+	"github.com/reclaro/cep/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quartzResults expands a Quartz expression
+func quartzResults(t *testing.T, input string) *parsers.CronResults {
+	holder, err := expressions.NewQuartzSyntax(input)
+	require.Nil(t, err)
+	p, err := parsers.NewDefaultParser(holder)
+	require.Nil(t, err)
+	res, err := p.Results()
+	require.Nil(t, err)
+	return res
+}
+
+func TestToVixie(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected []string
+		warnings []string
+	}{
+		{"exact", "0 0/15 9-17 ? * MON-FRI", []string{"*/15 9-17 * * 1-5"}, nil},
+		{"days of the month", "0 30 6 1,15 JAN ?", []string{"30 6 1,15 1 *"}, nil},
+		{"last day", "0 15 10 L 3-9 ?", []string{"15 10 31 3,5,7,8 *", "15 10 30 4,6,9 *"}, nil},
+		{"last day of february", "0 15 10 L-1 2 ?", []string{"15 10 27 2 *"},
+			[]string{"in the leap years February has 29 days, the expression runs on February 27 instead of February 28"}},
+		{"years", "0 0 0 1 1 ? 2030", []string{"0 0 1 1 *"},
+			[]string{"the years 2030 are dropped, the expressions run every year"}},
+		{"last friday", "0 15 10 ? * 6L", nil, []string{"the last Friday of the month has no equivalent"}},
+		{"third monday", "0 15 10 ? * MON#3", nil, []string{"the third Monday of the month has no equivalent"}},
+		{"weekday", "0 15 10 LW * ?", nil, []string{"the last weekday of the month has no equivalent"}},
+		{"seconds", "0/30 15 10 * * ?", nil, []string{"the seconds 0,30 have no equivalent, Vixie cron runs at second 0"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			c := ToVixie(quartzResults(t, tc.input))
+			assert.Equal(t, tc.expected, c.Expressions)
+			assert.Equal(t, tc.warnings, c.Warnings)
+			assert.Equal(t, len(tc.warnings) > 0, c.Lossy)
+		})
+	}
+}
+
+func TestToVixieBothDays(t *testing.T) {
+	holder, err := expressions.NewSpringSyntax("0 0 0 1 * MON")
+	require.Nil(t, err)
+	p, err := parsers.NewDefaultParser(holder)
+	require.Nil(t, err)
+	res, err := p.Results()
+	require.Nil(t, err)
+	c := ToVixie(res)
+	assert.Empty(t, c.Expressions)
+	assert.True(t, c.Lossy)
+}
+
+func TestToVixieEvery(t *testing.T) {
+	holder, err := expressions.NewRobfigSyntax("@every 1h")
+	require.Nil(t, err)
+	p, err := parsers.NewDefaultParser(holder)
+	require.Nil(t, err)
+	res, err := p.Results()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"the constant interval of 1h0m0s has no equivalent"}, ToVixie(res).Warnings)
+}
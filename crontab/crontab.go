@@ -0,0 +1,257 @@
+package crontab
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/reclaro/cep/expressions"
+	"github.com/reclaro/cep/parsers"
+)
+
+// scheduleFields is the number of time fields in a crontab line
+const scheduleFields = 5
+
+// annotationPrefix is the prefix of the annotations in the comments, e.g. # cep:duration=20m
+const annotationPrefix = "cep:"
+
+// envLine matches the environment settings in the form NAME=value
+var envLine = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// Entry is a single job of a crontab file
+type Entry struct {
+	// Line is the line number of the entry in the file, starting from 1
+	Line int
+	// Fields contains the 5 time fields as written in the file
+	Fields []string
+	// Command is the command to execute, including its arguments
+	Command string
+	// Results contains the expanded values of the time fields
+	Results *parsers.CronResults
+	// Annotations contains the annotations found in the comment lines right before the entry,
+	// e.g. the comment "# cep:duration=20m cep:lock=db" sets the keys duration and lock
+	Annotations map[string]string
+	// changed tells if the time fields were replaced with SetFields
+	changed bool
+}
+
+// Expression returns the cron expression of the entry in the format accepted by the DefaultSyntax
+func (e *Entry) Expression() string {
+	return strings.Join(e.Fields, " ") + " " + e.Command
+}
+
+// File represents a parsed crontab file. The original lines are kept so that the file can be written
+// back preserving comments, empty lines and environment settings
+type File struct {
+	// Entries contains the jobs in the order they appear in the file
+	Entries []*Entry
+	// Env contains the environment settings in the format NAME=value
+	Env []string
+	// lines are the raw lines of the file
+	lines []string
+	// entries maps the index of a line to the entry defined on it
+	entries map[int]*Entry
+}
+
+// Load reads and parses the crontab file at the given path
+func Load(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a crontab from r. Every job line is validated with the DefaultSyntax and expanded with the
+// DefaultParser, the first invalid line stops the parsing and it is reported in the returned error
+func Parse(r io.Reader) (*File, error) {
+	f := &File{entries: map[int]*Entry{}}
+	annotations := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		f.lines = append(f.lines, line)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			// the annotations are only for the entry right after the comments
+			annotations = map[string]string{}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			parseAnnotations(trimmed, annotations)
+			continue
+		}
+		if m := envLine.FindStringSubmatch(trimmed); m != nil {
+			f.Env = append(f.Env, m[1]+"="+strings.Trim(m[2], `"'`))
+			continue
+		}
+		e, err := parseEntry(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", len(f.lines), err.Error())
+		}
+		e.Line = len(f.lines)
+		e.Annotations = annotations
+		annotations = map[string]string{}
+		f.entries[len(f.lines)-1] = e
+		f.Entries = append(f.Entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseEntry splits a job line in time fields and command and it expands the time fields
+func parseEntry(line string) (*Entry, error) {
+	if strings.HasPrefix(line, "@") {
+		parts := splitFields(line, 1)
+		schedule, ok := expressions.VixieMacro(parts[0])
+		if !ok || len(parts) != 2 {
+			return nil, errors.New(fmt.Sprintf("Unsupported macro '%s'", parts[0]))
+		}
+		line = schedule + " " + parts[1]
+	}
+	parts := splitFields(line, scheduleFields)
+	if len(parts) != scheduleFields+1 {
+		return nil, errors.New(fmt.Sprintf("Invalid crontab line '%s', expected %d time fields and a command", line, scheduleFields))
+	}
+	e := &Entry{Fields: parts[:scheduleFields], Command: parts[scheduleFields]}
+	res, err := Expand(e.Expression())
+	if err != nil {
+		return nil, err
+	}
+	e.Results = res
+	return e, nil
+}
+
+// parseAnnotations adds to annotations the words of the comment in the format cep:key=value
+func parseAnnotations(comment string, annotations map[string]string) {
+	for _, word := range strings.Fields(strings.TrimPrefix(comment, "#")) {
+		if !strings.HasPrefix(word, annotationPrefix) {
+			continue
+		}
+		kv := strings.SplitN(strings.TrimPrefix(word, annotationPrefix), "=", 2)
+		if len(kv) == 2 && kv[0] != "" {
+			annotations[kv[0]] = kv[1]
+		}
+	}
+}
+
+// Expand validates a cron expression with a command with the DefaultSyntax and it expands it with the
+// DefaultParser
+func Expand(expression string) (*parsers.CronResults, error) {
+	holder, err := expressions.NewDefaultSyntax(expression)
+	if err != nil {
+		return nil, err
+	}
+	p, err := parsers.NewDefaultParser(holder)
+	if err != nil {
+		return nil, err
+	}
+	return p.Results()
+}
+
+// placeholder is the command added to the expressions without a command to validate them
+const placeholder = "command"
+
+// ExpandSchedule expands a cron expression that can have or not have the command, e.g. "0 0 1,31 * *" or
+// "@daily". The command of the results is empty when the expression has no command
+func ExpandSchedule(expression string) (*parsers.CronResults, error) {
+	fields := strings.Fields(expression)
+	if len(fields) != scheduleFields && (len(fields) != 1 || !strings.HasPrefix(fields[0], "@")) {
+		e, err := parseEntry(strings.TrimSpace(expression))
+		if err != nil {
+			return nil, err
+		}
+		return e.Results, nil
+	}
+	e, err := parseEntry(strings.Join(fields, " ") + " " + placeholder)
+	if err != nil {
+		// the placeholder is not part of the expression of the user
+		return nil, errors.New(strings.Replace(err.Error(), " "+placeholder, "", 1))
+	}
+	e.Results.Command = ""
+	return e.Results, nil
+}
+
+// splitFields splits the line on white spaces in n fields plus the remaining part of the line,
+// the remaining part is returned as it is
+func splitFields(line string, n int) []string {
+	parts := []string{}
+	rest := strings.TrimSpace(line)
+	for i := 0; i < n && rest != ""; i++ {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			parts = append(parts, rest)
+			rest = ""
+			break
+		}
+		parts = append(parts, rest[:end])
+		rest = strings.TrimSpace(rest[end:])
+	}
+	if rest != "" {
+		parts = append(parts, rest)
+	}
+	return parts
+}
+
+// SetFields replaces the time fields of the entry and it expands them again.
+// The entry is not modified if the new fields are not valid
+func (f *File) SetFields(e *Entry, fields []string) error {
+	if len(fields) != scheduleFields {
+		return errors.New(fmt.Sprintf("Expected %d time fields, found %d", scheduleFields, len(fields)))
+	}
+	res, err := Expand(strings.Join(fields, " ") + " " + e.Command)
+	if err != nil {
+		return err
+	}
+	e.Fields = append([]string{}, fields...)
+	e.Results = res
+	e.changed = true
+	return nil
+}
+
+// Clone returns a deep copy of the file, the entries of the copy can be changed without affecting
+// the original file
+func (f *File) Clone() *File {
+	c := &File{
+		Env:     append([]string{}, f.Env...),
+		lines:   append([]string{}, f.lines...),
+		entries: map[int]*Entry{},
+	}
+	for i := range f.lines {
+		e, ok := f.entries[i]
+		if !ok {
+			continue
+		}
+		ce := *e
+		ce.Fields = append([]string{}, e.Fields...)
+		ce.Annotations = map[string]string{}
+		for k, v := range e.Annotations {
+			ce.Annotations[k] = v
+		}
+		c.entries[i] = &ce
+		c.Entries = append(c.Entries, &ce)
+	}
+	return c
+}
+
+// String returns the content of the crontab file. Comments, empty lines and environment settings
+// are returned as they were in the original file, as the entries whose fields were not changed,
+// the changed entries are written with their current fields
+func (f *File) String() string {
+	var b strings.Builder
+	for i, line := range f.lines {
+		if e, ok := f.entries[i]; ok && e.changed {
+			line = e.Expression()
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
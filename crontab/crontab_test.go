@@ -0,0 +1,124 @@
+package crontab
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sample = `# backups
+SHELL=/bin/sh
+MAILTO = "ops@example.com"
+
+*/15 0 1,15 * 1-5 /usr/bin/find / -name core
+@daily	/usr/local/bin/backup.sh --full
+`
+
+func TestParse(t *testing.T) {
+	f, err := Parse(strings.NewReader(sample))
+	require.Nil(t, err)
+	require.Len(t, f.Entries, 2)
+	assert.Equal(t, []string{"SHELL=/bin/sh", "MAILTO=ops@example.com"}, f.Env)
+
+	e := f.Entries[0]
+	assert.Equal(t, 5, e.Line)
+	assert.Equal(t, []string{"*/15", "0", "1,15", "*", "1-5"}, e.Fields)
+	assert.Equal(t, "/usr/bin/find / -name core", e.Command)
+	assert.Equal(t, []int{0, 15, 30, 45}, e.Results.Minute)
+
+	e = f.Entries[1]
+	assert.Equal(t, []string{"0", "0", "*", "*", "*"}, e.Fields)
+	assert.Equal(t, "/usr/local/bin/backup.sh --full", e.Command)
+}
+
+func TestParseAnnotations(t *testing.T) {
+	input := `# cep:duration=20m
+# runs the backup cep:lock=db
+0 3 * * * /bin/backup
+# cep:duration=5m
+
+0 4 * * * /bin/report
+`
+	f, err := Parse(strings.NewReader(input))
+	require.Nil(t, err)
+	require.Len(t, f.Entries, 2)
+	assert.Equal(t, map[string]string{"duration": "20m", "lock": "db"}, f.Entries[0].Annotations)
+	// the empty line separates the comment from the entry
+	assert.Empty(t, f.Entries[1].Annotations)
+}
+
+func TestParseInvalidLine(t *testing.T) {
+	tcs := []struct {
+		name  string
+		input string
+	}{
+		{"missing command", "* * * * *"},
+		{"invalid field", "* * 99 * * /bin/ls"},
+		{"unsupported macro", "@reboot /bin/ls"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.input))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestSetFieldsAndString(t *testing.T) {
+	f, err := Parse(strings.NewReader(sample))
+	require.Nil(t, err)
+	c := f.Clone()
+
+	err = c.SetFields(c.Entries[0], []string{"5/15", "0", "1,15", "*", "1-5"})
+	require.Nil(t, err)
+	assert.Equal(t, []int{5, 20, 35, 50}, c.Entries[0].Results.Minute)
+	assert.Contains(t, c.String(), "5/15 0 1,15 * 1-5 /usr/bin/find / -name core\n")
+	assert.Contains(t, c.String(), "# backups\nSHELL=/bin/sh\n")
+	// the entries that were not changed keep their line
+	assert.Contains(t, c.String(), "\n@daily\t/usr/local/bin/backup.sh --full\n")
+	// the original file is not changed
+	assert.Equal(t, "*/15", f.Entries[0].Fields[0])
+
+	err = c.SetFields(c.Entries[0], []string{"99", "0", "1,15", "*", "1-5"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "5/15", c.Entries[0].Fields[0])
+}
+
+func TestStringUnchanged(t *testing.T) {
+	input := sample + "0   3 * * *   /bin/x\n@hourly /bin/y\n"
+	f, err := Parse(strings.NewReader(input))
+	require.Nil(t, err)
+	assert.Equal(t, input, f.String())
+	assert.Equal(t, input, f.Clone().String())
+}
+
+func TestExpandSchedule(t *testing.T) {
+	tcs := []struct {
+		input   string
+		fields  []int
+		command string
+		err     string
+	}{
+		{"0 0 1,31 * *", []int{1, 31}, "", ""},
+		{"@monthly", []int{1}, "", ""},
+		{"0 0 1,31 * * /bin/ls -l", []int{1, 31}, "/bin/ls -l", ""},
+		{"0 0 99 * *", nil, "", "Day of the Month value is not in the allowed interval [1 31]\n"},
+		{"0 0 *", nil, "", "Invalid crontab line '0 0 *', expected 5 time fields and a command"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.input, func(t *testing.T) {
+			res, err := ExpandSchedule(tc.input)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tc.fields, res.DayMonth)
+			assert.Equal(t, tc.command, res.Command)
+		})
+	}
+}
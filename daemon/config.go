@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/reclaro/cep/crontab"
+)
+
+/*
+Config is a sidecar configuration of the jobs, for the crontab files that cannot be annotated. It maps the
+name of a job to its options, with the same keys and values of the annotations without cep:, e.g.
+
+	{
+	  "*": {"timeout": "1h"},
+	  "backup": {"retries": "3", "backoff": "1m"}
+	}
+
+The options of "*" are the defaults of all the jobs. The annotations in the crontab files win over the
+options of the configuration. The options and the names of the jobs are checked, so that a typo does not
+go unnoticed.
+*/
+type Config map[string]map[string]string
+
+// LoadConfig reads a sidecar configuration from a JSON file
+func LoadConfig(path string) (Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := Config{}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid configuration file '%s': %s", path, err.Error()))
+	}
+	return c, nil
+}
+
+// Apply adds the options of the configuration to the annotations of the entries of the files. It returns
+// an error if an option is unknown or a job of the configuration is not in the files, the files are not
+// changed in that case
+func (c Config) Apply(files []*crontab.File) error {
+	names := map[string]bool{"*": true}
+	for _, f := range files {
+		for _, e := range f.Entries {
+			names[jobName(e)] = true
+		}
+	}
+	jobs := []string{}
+	for name := range c {
+		jobs = append(jobs, name)
+	}
+	sort.Strings(jobs)
+	for _, name := range jobs {
+		if !names[name] {
+			return errors.New(fmt.Sprintf("The configuration has the job '%s' that is not in the crontab files", name))
+		}
+		if key := unknownKey(c[name]); key != "" {
+			return errors.New(fmt.Sprintf("Unknown option '%s' of the job '%s' in the configuration", key, name))
+		}
+	}
+	for _, f := range files {
+		for _, e := range f.Entries {
+			annotations := map[string]string{}
+			for k, v := range c["*"] {
+				annotations[k] = v
+			}
+			for k, v := range c[jobName(e)] {
+				annotations[k] = v
+			}
+			for k, v := range e.Annotations {
+				annotations[k] = v
+			}
+			e.Annotations = annotations
+		}
+	}
+	return nil
+}
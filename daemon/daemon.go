@@ -0,0 +1,370 @@
+/*
+Package daemon runs the jobs of crontab files as a small cron replacement, e.g. inside a container.
+Every job runs its command with /bin/sh -c in the environment of the daemon plus the environment settings
+of its crontab file. The output of the commands is captured and every run is logged as a JSON object on
+a single line.
+*/
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reclaro/cep/clock"
+	"github.com/reclaro/cep/crontab"
+	"github.com/reclaro/cep/parsers"
+	"github.com/reclaro/cep/scheduler"
+	"github.com/reclaro/cep/state"
+)
+
+// maxOutput is the number of bytes of the standard output and error kept for each run
+const maxOutput = 64 * 1024
+
+// outputDelay is how long the output of a command is read after the shell exits, the commands started
+// in the background by the shell can keep the output open long after the run finished
+const outputDelay = 100 * time.Millisecond
+
+// errTimedOut is the cause of the cancellation of an attempt that timed out
+var errTimedOut = errors.New("The attempt timed out")
+
+// Result is the outcome of a run of a job, of its last attempt when the run is retried
+type Result struct {
+	Job      string
+	Attempt  int
+	Start    time.Time
+	Duration time.Duration
+	ExitCode int
+	TimedOut bool
+	Stdout   string
+	Stderr   string
+	Err      error
+}
+
+// Daemon runs the jobs of crontab files at their schedules. Shell is the shell that runs the commands and
+// Clock the clock of the scheduler, Store keeps the state of the jobs, e.g. the last run used to catch up
+// the runs missed while the daemon was not running, in memory unless it is replaced. They can be set before
+// Start
+type Daemon struct {
+	Shell string
+	Clock clock.Clock
+	Store state.Store
+
+	mu        sync.Mutex
+	jobs      []*Job
+	triggers  map[*Job]*trigger
+	started   bool
+	log       *Logger
+	scheduler *scheduler.Scheduler
+	metrics   *metrics
+}
+
+// New returns a daemon for the jobs of the crontab files that logs to out, the schedules are in the
+// location, the local one if it is nil. It returns an error if the annotations of a job are invalid
+func New(files []*crontab.File, location *time.Location, out io.Writer) (*Daemon, error) {
+	d := &Daemon{
+		Shell:     "/bin/sh",
+		Clock:     clock.New(),
+		Store:     state.NewMemory(),
+		log:       NewLogger(out),
+		scheduler: scheduler.New(location),
+		metrics:   newMetrics(),
+		triggers:  map[*Job]*trigger{},
+	}
+	var err error
+	if d.jobs, err = newJobs(files); err != nil {
+		return nil, err
+	}
+	d.scheduler.SkipHandler = func(e scheduler.Entry) {
+		d.log.Log(Event{Time: d.Clock.Now(), Level: "warning", Message: "job skipped, the previous run is still running", Job: e.Name})
+	}
+	return d, nil
+}
+
+// newJobs returns the jobs of the entries of the crontab files, it checks their dependencies
+func newJobs(files []*crontab.File) ([]*Job, error) {
+	jobs := []*Job{}
+	for _, f := range files {
+		for _, e := range f.Entries {
+			j, err := NewJob(e, f.Env)
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, j)
+		}
+	}
+	if _, err := Dependencies(jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Jobs returns the jobs of the daemon
+func (d *Daemon) Jobs() []*Job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]*Job{}, d.jobs...)
+}
+
+// Start registers the jobs in the scheduler and starts running them, the jobs with a catch-up policy run
+// the runs missed since their last run
+func (d *Daemon) Start(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.scheduler.Clock = d.Clock
+	for _, j := range d.jobs {
+		if err := d.register(j); err != nil {
+			return err
+		}
+	}
+	if err := d.scheduler.Start(ctx); err != nil {
+		return err
+	}
+	d.started = true
+	d.log.Log(Event{Time: d.Clock.Now(), Message: "daemon started"})
+	d.logScheduled(d.jobs)
+	return nil
+}
+
+// register adds a job to the scheduler, it must be called holding the lock
+func (d *Daemon) register(j *Job) error {
+	js, err := d.Store.Get(j.Name)
+	if err != nil {
+		return err
+	}
+	var schedule parsers.Schedule = j.Entry.Results
+	if len(j.After) > 0 {
+		schedule = triggered{}
+	}
+	j.id = d.scheduler.Add(j.Name, schedule, func(ctx context.Context) error {
+		return d.Run(ctx, j).Err
+	})
+	d.scheduler.SetConcurrency(j.id, j.Concurrency)
+	if js.Scheduled.IsZero() {
+		// the start is the reference to find the runs missed before the first run
+		d.update(j, func(js *state.Job) {
+			js.Scheduled = d.Clock.Now()
+		})
+	}
+	d.scheduler.SetCatchUp(j.id, j.CatchUp, js.Scheduled)
+	return nil
+}
+
+// logScheduled logs the next run of the jobs
+func (d *Daemon) logScheduled(jobs []*Job) {
+	next := map[int]time.Time{}
+	for _, e := range d.scheduler.Entries() {
+		next[e.ID] = e.Next
+	}
+	for _, j := range jobs {
+		event := Event{Time: d.Clock.Now(), Message: "job scheduled", Job: j.Name}
+		if n := next[j.id]; !n.IsZero() {
+			event.Next = &n
+		}
+		d.log.Log(event)
+	}
+}
+
+// Stop stops running new jobs and it waits for the running ones until the context is done, then their
+// commands are killed
+func (d *Daemon) Stop(ctx context.Context) error {
+	err := d.scheduler.Stop(ctx)
+	d.log.Log(Event{Time: d.Clock.Now(), Message: "daemon stopped"})
+	return err
+}
+
+// Run runs the command of a job, it waits for the command to finish and it logs the result. A failed
+// attempt is retried as set by the job, the command is killed when the context is done or the attempt times out
+func (d *Daemon) Run(ctx context.Context, j *Job) Result {
+	scheduled := scheduler.ScheduledTime(ctx)
+	if !scheduled.IsZero() {
+		d.update(j, func(js *state.Job) {
+			js.Scheduled = scheduled
+		})
+	}
+	for attempt := 1; ; attempt++ {
+		res := d.attempt(ctx, j, scheduled, attempt)
+		if res.Err == nil || attempt > j.Retries || ctx.Err() != nil {
+			d.metrics.observeRun(j.Name, res, res.Start.Add(res.Duration))
+			if res.Err == nil {
+				d.succeeded(j, res.Start.Add(res.Duration))
+			}
+			return res
+		}
+		delay := j.Delay(attempt)
+		d.log.Log(Event{Time: d.Clock.Now(), Level: "warning", Message: "job retry scheduled", Job: j.Name, Attempt: attempt + 1, RetryIn: delay.String()})
+		select {
+		case <-d.Clock.After(delay):
+		case <-ctx.Done():
+			d.metrics.observeRun(j.Name, res, res.Start.Add(res.Duration))
+			return res
+		}
+	}
+}
+
+// attempt runs the command of a job once with the timeout of the job, it logs the result and it records
+// it in the state of the job
+func (d *Daemon) attempt(ctx context.Context, j *Job, scheduled time.Time, attempt int) Result {
+	event := Event{Time: d.Clock.Now(), Message: "job started", Job: j.Name, Command: j.Command}
+	if !scheduled.IsZero() {
+		event.Scheduled = &scheduled
+	}
+	if attempt > 1 {
+		event.Attempt = attempt
+	}
+	d.log.Log(event)
+
+	// the timeout uses the clock of the daemon, as the backoff
+	attemptCtx, cancel := context.WithCancelCause(ctx)
+	var timer clock.Timer
+	if j.Timeout > 0 {
+		timer = d.Clock.NewTimer(j.Timeout)
+		go func() {
+			select {
+			case <-timer.C():
+				cancel(errTimedOut)
+			case <-attemptCtx.Done():
+			}
+		}()
+	}
+	res := d.exec(attemptCtx, j)
+	if timer != nil {
+		timer.Stop()
+	}
+	cancel(nil)
+	d.metrics.observeAttempt(j.Name, res.Duration)
+	res.Attempt = attempt
+	if res.Err != nil && context.Cause(attemptCtx) == errTimedOut && ctx.Err() == nil {
+		res.TimedOut = true
+		res.Err = errors.New(fmt.Sprintf("The command timed out after %s", j.Timeout))
+	}
+
+	a := state.Attempt{
+		Scheduled: scheduled,
+		Attempt:   attempt,
+		Start:     res.Start,
+		End:       res.Start.Add(res.Duration),
+		ExitCode:  res.ExitCode,
+		Duration:  res.Duration,
+		TimedOut:  res.TimedOut,
+	}
+	event = Event{
+		Time:     d.Clock.Now(),
+		Message:  "job finished",
+		Job:      j.Name,
+		Start:    &res.Start,
+		Duration: res.Duration.String(),
+		ExitCode: &res.ExitCode,
+		Stdout:   res.Stdout,
+		Stderr:   res.Stderr,
+	}
+	if attempt > 1 {
+		event.Attempt = attempt
+	}
+	if res.Err != nil {
+		a.Error = res.Err.Error()
+		event.Level = "error"
+		event.Message = "job failed"
+		event.Error = res.Err.Error()
+	}
+	d.update(j, func(js *state.Job) {
+		js.Record(a)
+	})
+	d.log.Log(event)
+	return res
+}
+
+// update changes the state of a job in the store, the errors of the store are logged
+func (d *Daemon) update(j *Job, f func(js *state.Job)) {
+	err := d.Store.Update(j.Name, func(js *state.Job) error {
+		f(js)
+		return nil
+	})
+	if err != nil {
+		d.log.Log(Event{Time: d.Clock.Now(), Level: "error", Message: "cannot save the state of the job", Job: j.Name, Error: err.Error()})
+	}
+}
+
+// exec runs the command of a job with the shell and it captures its output, the process group of the
+// command is killed when the context is done. The run finishes when the shell exits, even if the commands
+// it started in the background are still running
+func (d *Daemon) exec(ctx context.Context, j *Job) Result {
+	cmd := exec.Command(d.Shell, "-c", j.Command)
+	cmd.WaitDelay = outputDelay
+	setProcessGroup(cmd)
+	cmd.Env = append(os.Environ(), j.Env...)
+	stdout := &limitedBuffer{max: maxOutput}
+	stderr := &limitedBuffer{max: maxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	res := Result{Job: j.Name, Start: d.Clock.Now()}
+	err := cmd.Start()
+	if err == nil {
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				killProcessGroup(cmd)
+			case <-done:
+			}
+		}()
+		err = cmd.Wait()
+		close(done)
+		if errors.Is(err, exec.ErrWaitDelay) {
+			// the shell succeeded but a command in the background still holds the output
+			err = nil
+		}
+	}
+	res.Duration = d.Clock.Since(res.Start)
+	res.Stdout = strings.TrimRight(stdout.String(), "\n")
+	res.Stderr = strings.TrimRight(stderr.String(), "\n")
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitCode()
+		res.Err = errors.New(fmt.Sprintf("The command exited with code %d", res.ExitCode))
+		if ctx.Err() != nil {
+			res.Err = errors.New(fmt.Sprintf("The command was killed: %s", ctx.Err().Error()))
+		} else if res.ExitCode < 0 {
+			res.Err = errors.New(fmt.Sprintf("The command was terminated: %s", exitErr.Error()))
+		}
+	default:
+		res.ExitCode = -1
+		res.Err = err
+	}
+	return res
+}
+
+// limitedBuffer keeps the first max bytes written to it and it discards the others
+type limitedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (lb *limitedBuffer) Write(p []byte) (int, error) {
+	if room := lb.max - lb.Len(); room < len(p) {
+		lb.truncated = true
+		if room > 0 {
+			lb.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return lb.Buffer.Write(p)
+}
+
+func (lb *limitedBuffer) String() string {
+	if lb.truncated {
+		return lb.Buffer.String() + "\n[truncated]"
+	}
+	return lb.Buffer.String()
+}
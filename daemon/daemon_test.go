@@ -0,0 +1,312 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/clock"
+	"github.com/reclaro/cep/crontab"
+	"github.com/reclaro/cep/scheduler"
+	"github.com/reclaro/cep/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a buffer safe to use from more goroutines
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.b.Write(p)
+}
+
+// events returns the events logged so far
+func (sb *syncBuffer) events(t *testing.T) []Event {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	res := []Event{}
+	for _, line := range strings.Split(strings.TrimSpace(sb.b.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var e Event
+		require.Nil(t, json.Unmarshal([]byte(line), &e), line)
+		res = append(res, e)
+	}
+	return res
+}
+
+func parse(t *testing.T, content string) *crontab.File {
+	f, err := crontab.Parse(strings.NewReader(content))
+	require.Nil(t, err)
+	return f
+}
+
+func TestRun(t *testing.T) {
+	f := parse(t, `GREETING="hello world"
+# cep:name=greet
+* * * * * echo $GREETING; echo oops >&2
+* * * * * exit 3
+* * * * * kill -9 $$
+`)
+	out := &syncBuffer{}
+	d, err := New([]*crontab.File{f}, time.UTC, out)
+	require.Nil(t, err)
+	jobs := d.Jobs()
+	require.Len(t, jobs, 3)
+	assert.Equal(t, "greet", jobs[0].Name)
+	assert.Equal(t, "exit 3", jobs[1].Name)
+
+	res := d.Run(context.Background(), jobs[0])
+	assert.Nil(t, res.Err)
+	assert.Equal(t, 0, res.ExitCode)
+	assert.Equal(t, "hello world", res.Stdout)
+	assert.Equal(t, "oops", res.Stderr)
+
+	res = d.Run(context.Background(), jobs[1])
+	assert.NotNil(t, res.Err)
+	assert.Equal(t, 3, res.ExitCode)
+
+	res = d.Run(context.Background(), jobs[2])
+	assert.NotNil(t, res.Err)
+	assert.Equal(t, -1, res.ExitCode)
+
+	events := out.events(t)
+	require.Len(t, events, 6)
+	assert.Equal(t, "job started", events[0].Message)
+	assert.Equal(t, "echo $GREETING; echo oops >&2", events[0].Command)
+	assert.Equal(t, "job finished", events[1].Message)
+	assert.Equal(t, "hello world", events[1].Stdout)
+	assert.Equal(t, 0, *events[1].ExitCode)
+	assert.Equal(t, "error", events[3].Level)
+	assert.Equal(t, "job failed", events[3].Message)
+	assert.Equal(t, 3, *events[3].ExitCode)
+}
+
+func TestRunCancel(t *testing.T) {
+	d, err := New(nil, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	res := d.Run(ctx, &Job{Name: "sleep", Command: "sleep 5"})
+	assert.NotNil(t, res.Err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestRunBackground(t *testing.T) {
+	d, err := New(nil, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	// the run finishes when the shell exits, the command in the background keeps the output open
+	start := time.Now()
+	res := d.Run(context.Background(), &Job{Name: "background", Command: "sleep 2 & echo started"})
+	assert.Nil(t, res.Err)
+	assert.Equal(t, "started", res.Stdout)
+	assert.True(t, time.Since(start) < time.Second)
+
+	res = d.Run(context.Background(), &Job{Name: "background", Command: "sleep 2 & exit 4"})
+	assert.NotNil(t, res.Err)
+	assert.Equal(t, 4, res.ExitCode)
+}
+
+func TestOutputLimit(t *testing.T) {
+	lb := &limitedBuffer{max: 4}
+	n, err := lb.Write([]byte("abc"))
+	assert.Equal(t, 3, n)
+	assert.Nil(t, err)
+	n, _ = lb.Write([]byte("def"))
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "abcd\n[truncated]", lb.String())
+}
+
+func TestSchedule(t *testing.T) {
+	f := parse(t, "*/5 * * * * echo tick\n")
+	start := time.Date(2021, 1, 1, 0, 1, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	out := &syncBuffer{}
+	d, err := New([]*crontab.File{f}, time.UTC, out)
+	require.Nil(t, err)
+	d.Clock = fake
+	require.Nil(t, d.Start(context.Background()))
+
+	fake.BlockUntil(1)
+	assert.Equal(t, []time.Time{start.Add(4 * time.Minute)}, fake.Timers())
+	fake.Advance(4 * time.Minute)
+	fake.BlockUntil(1)
+	require.Nil(t, d.Stop(context.Background()))
+
+	messages := []string{}
+	for _, e := range out.events(t) {
+		messages = append(messages, e.Message)
+	}
+	assert.Equal(t, []string{"daemon started", "job scheduled", "job started", "job finished", "daemon stopped"}, messages)
+	events := out.events(t)
+	assert.Equal(t, start.Add(4*time.Minute), *events[1].Next)
+	assert.Equal(t, "tick", events[3].Stdout)
+}
+
+func TestConcurrencyAnnotation(t *testing.T) {
+	f := parse(t, `# cep:concurrency=Forbid
+* * * * * sleep 1
+# cep:concurrency=replace
+* * * * * sleep 2
+* * * * * sleep 3
+`)
+	d, err := New([]*crontab.File{f}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	policies := []scheduler.Policy{}
+	for _, j := range d.Jobs() {
+		policies = append(policies, j.Concurrency)
+	}
+	assert.Equal(t, []scheduler.Policy{scheduler.Forbid, scheduler.Replace, scheduler.Allow}, policies)
+
+	f = parse(t, "# cep:concurrency=queue\n* * * * * sleep 1\n")
+	_, err = New([]*crontab.File{f}, time.UTC, &syncBuffer{})
+	assert.EqualError(t, err, "line 2: Invalid concurrency policy 'queue', expected one of allow, forbid, replace")
+}
+
+func TestForbid(t *testing.T) {
+	f := parse(t, "# cep:concurrency=forbid cep:name=slow\n* * * * * sleep 5\n")
+	fake := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 30, 0, time.UTC))
+	out := &syncBuffer{}
+	d, err := New([]*crontab.File{f}, time.UTC, out)
+	require.Nil(t, err)
+	d.Clock = fake
+	require.Nil(t, d.Start(context.Background()))
+	for i := 0; i < 2; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(time.Minute)
+	}
+	fake.BlockUntil(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, d.Stop(ctx))
+	count := map[string]int{}
+	for _, e := range out.events(t) {
+		count[e.Message]++
+	}
+	assert.Equal(t, 1, count["job started"])
+	assert.Equal(t, 1, count["job skipped, the previous run is still running"])
+}
+
+func TestCatchUpAnnotations(t *testing.T) {
+	tests := []struct {
+		annotations string
+		catchUp     scheduler.CatchUp
+		err         string
+	}{
+		{"", scheduler.CatchUp{}, ""},
+		{"cep:catchup=once", scheduler.CatchUp{Policy: scheduler.CatchUpOnce}, ""},
+		{"cep:catchup=all cep:catchup-limit=3", scheduler.CatchUp{Policy: scheduler.CatchUpAll, Limit: 3}, ""},
+		{"cep:catchup=deadline cep:catchup-deadline=2h", scheduler.CatchUp{Policy: scheduler.CatchUpDeadline, Deadline: 2 * time.Hour}, ""},
+		{"cep:catchup=deadline", scheduler.CatchUp{}, "line 2: Invalid catch-up deadline '', the deadline policy needs a duration, e.g. cep:catchup-deadline=2h"},
+		{"cep:catchup=all cep:catchup-limit=-1", scheduler.CatchUp{}, "line 2: Invalid catch-up limit '-1', expected a number of runs greater than 0"},
+		{"cep:catchup=all cep:catchup-limit=0", scheduler.CatchUp{}, "line 2: Invalid catch-up limit '0', expected a number of runs greater than 0"},
+		{"cep:catchup=always", scheduler.CatchUp{}, "line 2: Invalid catch-up policy 'always', expected one of none, once, all, deadline"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.annotations, func(t *testing.T) {
+			d, err := New([]*crontab.File{parse(t, "# "+tt.annotations+"\n0 3 * * * backup\n")}, time.UTC, &syncBuffer{})
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.Nil(t, err)
+			assert.Equal(t, tt.catchUp, d.Jobs()[0].CatchUp)
+		})
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cep")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "state.json")
+	last := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Nil(t, ioutil.WriteFile(stateFile, []byte(`{"hourly": {"scheduled": "2021-01-01T00:00:00Z"}}`), 0644))
+	store, err := state.NewFile(stateFile)
+	require.Nil(t, err)
+
+	f := parse(t, `# cep:name=hourly cep:catchup=all cep:catchup-limit=2
+0 * * * * true
+# cep:name=new cep:catchup=once
+0 * * * * true
+`)
+	fake := clock.NewFake(last.Add(3*time.Hour + 30*time.Minute))
+	out := &syncBuffer{}
+	d, err := New([]*crontab.File{f}, time.UTC, out)
+	require.Nil(t, err)
+	d.Clock = fake
+	d.Store = store
+	require.Nil(t, d.Start(context.Background()))
+
+	finished := func() int {
+		n := 0
+		for _, e := range out.events(t) {
+			if e.Message == "job finished" {
+				n++
+			}
+		}
+		return n
+	}
+	for i := 0; finished() < 2 && i < 1000; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	require.Nil(t, d.Stop(context.Background()))
+
+	scheduled := []time.Time{}
+	for _, e := range out.events(t) {
+		if e.Message == "job started" {
+			assert.Equal(t, "hourly", e.Job)
+			scheduled = append(scheduled, *e.Scheduled)
+		}
+	}
+	assert.Equal(t, []time.Time{last.Add(2 * time.Hour), last.Add(3 * time.Hour)}, scheduled)
+
+	reloaded, err := state.NewFile(stateFile)
+	require.Nil(t, err)
+	js, _ := reloaded.Get("hourly")
+	assert.True(t, last.Add(3*time.Hour).Equal(js.Scheduled))
+	js, _ = reloaded.Get("new")
+	assert.True(t, fake.Now().Equal(js.Scheduled))
+	assert.True(t, js.Start.IsZero())
+}
+
+func TestState(t *testing.T) {
+	f := parse(t, "# cep:name=fail\n* * * * * exit 4\n")
+	fake := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 30, 0, time.UTC))
+	d, err := New([]*crontab.File{f}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	d.Clock = fake
+	require.Nil(t, d.Start(context.Background()))
+	fake.BlockUntil(1)
+	fake.Advance(90 * time.Second)
+	for i := 0; i < 1000; i++ {
+		if js, _ := d.Store.Get("fail"); !js.End.IsZero() {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	require.Nil(t, d.Stop(context.Background()))
+
+	js, err := d.Store.Get("fail")
+	require.Nil(t, err)
+	assert.Equal(t, time.Date(2021, 1, 1, 0, 1, 0, 0, time.UTC), js.Scheduled)
+	assert.Equal(t, fake.Now(), js.Start)
+	assert.Equal(t, fake.Now(), js.End)
+	assert.Equal(t, 4, js.ExitCode)
+	assert.Equal(t, time.Duration(0), js.Duration)
+}
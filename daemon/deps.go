@@ -0,0 +1,171 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Graph is the graph of the dependencies between the jobs, an edge goes from a job to the jobs that run
+// after it succeeds
+type Graph struct {
+	// Jobs are the names of the jobs in an order where every job follows the jobs it depends on
+	Jobs []string
+	// After maps the name of a job to the names of the jobs it runs after
+	After map[string][]string
+	// Dependents maps the name of a job to the names of the jobs that run after it, sorted
+	Dependents map[string][]string
+	// Windows maps the name of a job with a window to its schedule and the length of the window
+	Windows map[string]string
+}
+
+// Dependencies returns the graph of the dependencies of the jobs. It returns an error if a job depends on
+// a missing job, on a name shared by more jobs or if the dependencies have a cycle
+func Dependencies(jobs []*Job) (*Graph, error) {
+	count := map[string]int{}
+	for _, j := range jobs {
+		count[j.Name]++
+	}
+	g := &Graph{After: map[string][]string{}, Dependents: map[string][]string{}, Windows: map[string]string{}}
+	after := g.After
+	for _, j := range jobs {
+		for _, a := range j.After {
+			switch {
+			case count[a] == 0:
+				return nil, errors.New(fmt.Sprintf("line %d: the job '%s' runs after the job '%s' that does not exist", j.Entry.Line, j.Name, a))
+			case count[a] > 1:
+				return nil, errors.New(fmt.Sprintf("line %d: the job '%s' runs after '%s' but more jobs have this name, set a unique cep:name", j.Entry.Line, j.Name, a))
+			}
+			g.Dependents[a] = append(g.Dependents[a], j.Name)
+			after[j.Name] = append(after[j.Name], a)
+		}
+		if j.Window > 0 {
+			g.Windows[j.Name] = fmt.Sprintf("%s for %s", strings.Join(j.Entry.Fields, " "), j.Window)
+		}
+	}
+	for _, dependents := range g.Dependents {
+		sort.Strings(dependents)
+	}
+
+	// a depth-first visit finds the cycles and sorts the jobs
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	status := map[string]int{}
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch status[name] {
+		case visiting:
+			return errors.New(fmt.Sprintf("The dependencies of the jobs have a cycle: %s -> %s", strings.Join(path, " -> "), name))
+		case visited:
+			return nil
+		}
+		status[name] = visiting
+		for _, a := range after[name] {
+			if err := visit(a, append(path, name)); err != nil {
+				return err
+			}
+		}
+		status[name] = visited
+		g.Jobs = append(g.Jobs, name)
+		return nil
+	}
+	for _, j := range jobs {
+		if err := visit(j.Name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// trigger is the state of a job that runs after other jobs
+type trigger struct {
+	// succeeded maps the jobs it depends on to the time of their last success since its last run
+	succeeded map[string]time.Time
+	// window is the start of the last window where it ran
+	window time.Time
+}
+
+// succeeded records the success of a job and it runs the jobs that depend on it when all the jobs they
+// depend on succeeded, within their window
+func (d *Daemon) succeeded(j *Job, at time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.started {
+		return
+	}
+	for _, dep := range d.jobs {
+		if !dep.runsAfter(j.Name) {
+			continue
+		}
+		t := d.triggers[dep]
+		if t == nil {
+			t = &trigger{succeeded: map[string]time.Time{}}
+			d.triggers[dep] = t
+		}
+		t.succeeded[j.Name] = at
+		start, open := dep.window(at)
+		if !open {
+			d.log.Log(Event{Time: d.Clock.Now(), Message: "job not triggered, outside its window", Job: dep.Name})
+			continue
+		}
+		if !start.IsZero() && !t.window.Before(start) {
+			// the job already ran in this window
+			continue
+		}
+		ready := true
+		for _, a := range dep.After {
+			if s, ok := t.succeeded[a]; !ok || s.Before(start) {
+				ready = false
+			}
+		}
+		if !ready {
+			continue
+		}
+		t.succeeded = map[string]time.Time{}
+		t.window = start
+		d.log.Log(Event{Time: d.Clock.Now(), Message: "job triggered by " + strings.Join(dep.After, ", "), Job: dep.Name})
+		if err := d.scheduler.Trigger(dep.id); err != nil {
+			d.log.Log(Event{Time: d.Clock.Now(), Level: "error", Message: "job not triggered", Job: dep.Name, Error: err.Error()})
+		}
+	}
+}
+
+// runsAfter tells if the job depends on the job with the name
+func (j *Job) runsAfter(name string) bool {
+	for _, a := range j.After {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+// window returns the start of the window of the job that contains t and whether t is in a window, the
+// start is zero when the job has no window
+func (j *Job) window(t time.Time) (time.Time, bool) {
+	if j.Window == 0 {
+		return time.Time{}, true
+	}
+	// the first run after t - window opens the window containing t, if it is not after t
+	start := j.Entry.Results.Next(t.Add(-j.Window))
+	if start.IsZero() || start.After(t) {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// triggered is the schedule of the jobs that run after other jobs, they never run at a time
+type triggered struct{}
+
+func (triggered) Next(t time.Time) time.Time {
+	return time.Time{}
+}
+
+func (triggered) Matches(t time.Time) bool {
+	return false
+}
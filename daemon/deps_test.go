@@ -0,0 +1,151 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/clock"
+	"github.com/reclaro/cep/crontab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDependencies(t *testing.T) {
+	d, err := New([]*crontab.File{parse(t, `# cep:name=report cep:after=load,extract cep:window=4h
+0 2 * * * /bin/report
+# cep:name=load cep:after=extract
+0 1 * * * /bin/load
+# cep:name=extract
+0 1 * * * /bin/extract
+`)}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	g, err := Dependencies(d.Jobs())
+	require.Nil(t, err)
+	assert.Equal(t, []string{"extract", "load", "report"}, g.Jobs)
+	assert.Equal(t, map[string][]string{"extract": {"load", "report"}, "load": {"report"}}, g.Dependents)
+	assert.Equal(t, map[string][]string{"report": {"load", "extract"}, "load": {"extract"}}, g.After)
+	assert.Equal(t, map[string]string{"report": "0 2 * * * for 4h0m0s"}, g.Windows)
+}
+
+func TestDependenciesInvalid(t *testing.T) {
+	tests := []struct {
+		crontab string
+		err     string
+	}{
+		{"# cep:after=missing\n0 1 * * * /bin/job\n", "line 2: the job '/bin/job' runs after the job 'missing' that does not exist"},
+		{"0 1 * * * same\n0 2 * * * same\n# cep:after=same\n0 1 * * * /bin/job\n", "line 4: the job '/bin/job' runs after 'same' but more jobs have this name, set a unique cep:name"},
+		{"# cep:name=a cep:after=b\n0 1 * * * a\n# cep:name=b cep:after=c\n0 1 * * * b\n# cep:name=c cep:after=a\n0 1 * * * c\n", "The dependencies of the jobs have a cycle: a -> b -> c -> a"},
+		{"# cep:name=a cep:after=a\n0 1 * * * a\n", "The dependencies of the jobs have a cycle: a -> a"},
+		{"# cep:window=1h\n0 1 * * * /bin/job\n", "line 2: The window needs the jobs to run after, e.g. cep:after=extract"},
+	}
+	for _, tt := range tests {
+		_, err := New([]*crontab.File{parse(t, tt.crontab)}, time.UTC, &syncBuffer{})
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	f := parse(t, "# cep:name=a\n0 1 * * * a\n# cep:after=a cep:window=4h\n0 2 * * * b\n")
+	d, err := New([]*crontab.File{f}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	j := d.Jobs()[1]
+	day := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		at    time.Duration
+		start time.Time
+		open  bool
+	}{
+		{time.Hour, time.Time{}, false},
+		{2 * time.Hour, day.Add(2 * time.Hour), true},
+		{5*time.Hour + 59*time.Minute, day.Add(2 * time.Hour), true},
+		{6 * time.Hour, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		start, open := j.window(day.Add(tt.at))
+		assert.Equal(t, tt.open, open, "at %s", tt.at)
+		assert.Equal(t, tt.start, start, "at %s", tt.at)
+	}
+	start, open := d.Jobs()[0].window(day)
+	assert.True(t, open)
+	assert.True(t, start.IsZero())
+}
+
+// finished waits for n runs of the jobs to finish and it returns the names of the jobs that finished or failed
+func finished(t *testing.T, out *syncBuffer, n int) []string {
+	names := []string{}
+	for i := 0; i < 1000; i++ {
+		names = []string{}
+		for _, e := range out.events(t) {
+			if e.Message == "job finished" || e.Message == "job failed" {
+				names = append(names, e.Job)
+			}
+		}
+		if len(names) >= n {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return names
+}
+
+func TestRunAfter(t *testing.T) {
+	f := parse(t, `# cep:name=extract
+0 * * * * true
+# cep:name=fail
+0 * * * * false
+# cep:name=load cep:after=extract
+* * * * * true
+# cep:name=never cep:after=extract,fail
+* * * * * true
+`)
+	out := &syncBuffer{}
+	d, err := New([]*crontab.File{f}, time.UTC, out)
+	require.Nil(t, err)
+	fake := clock.NewFake(time.Date(2021, 1, 1, 0, 59, 0, 0, time.UTC))
+	d.Clock = fake
+	require.Nil(t, d.Start(context.Background()))
+	// the jobs that run after other jobs are not scheduled
+	fake.BlockUntil(1)
+	assert.Equal(t, []time.Time{time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC)}, fake.Timers())
+
+	fake.Advance(time.Minute)
+	assert.ElementsMatch(t, []string{"extract", "fail", "load"}, finished(t, out, 3))
+	require.Nil(t, d.Stop(context.Background()))
+	assert.ElementsMatch(t, []string{"extract", "fail", "load"}, finished(t, out, 3))
+}
+
+func TestRunAfterWindow(t *testing.T) {
+	f := parse(t, `# cep:name=extract
+0 * * * * true
+# cep:name=report cep:after=extract cep:window=90m
+0 2 * * * true
+`)
+	out := &syncBuffer{}
+	d, err := New([]*crontab.File{f}, time.UTC, out)
+	require.Nil(t, err)
+	fake := clock.NewFake(time.Date(2021, 1, 1, 0, 30, 0, 0, time.UTC))
+	d.Clock = fake
+	require.Nil(t, d.Start(context.Background()))
+	defer d.Stop(context.Background())
+
+	// extract runs at 01:00, 02:00, 03:00 and 04:00, report runs only after the run at 02:00 in its window
+	// from 02:00 to 03:30
+	for i := 1; i <= 4; i++ {
+		fake.BlockUntil(1)
+		fake.Set(time.Date(2021, 1, 1, i, 0, 0, 0, time.UTC))
+		n := i
+		if i >= 2 {
+			n++
+		}
+		finished(t, out, n)
+	}
+	fake.BlockUntil(1)
+	count := map[string]int{}
+	for _, e := range out.events(t) {
+		if e.Message == "job finished" {
+			count[e.Job]++
+		}
+	}
+	assert.Equal(t, map[string]int{"extract": 4, "report": 1}, count)
+}
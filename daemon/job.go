@@ -0,0 +1,226 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/crontab"
+	"github.com/reclaro/cep/scheduler"
+)
+
+// the defaults of the backoff between the retries
+const (
+	defaultBackoff    = 10 * time.Second
+	defaultBackoffMax = 10 * time.Minute
+	defaultJitter     = 0.2
+)
+
+// annotationKeys are the keys of the annotations known by cep, duration and lock are used by cep overlap
+var annotationKeys = map[string]bool{
+	"name":             true,
+	"concurrency":      true,
+	"catchup":          true,
+	"catchup-limit":    true,
+	"catchup-deadline": true,
+	"timeout":          true,
+	"retries":          true,
+	"backoff":          true,
+	"backoff-max":      true,
+	"jitter":           true,
+	"after":            true,
+	"window":           true,
+	"duration":         true,
+	"lock":             true,
+}
+
+// Job is a job of a crontab file, the options are set by the annotations of the entry
+type Job struct {
+	// Name is the value of the annotation cep:name or the command when the annotation is missing
+	Name    string
+	Command string
+	Env     []string
+	Entry   *crontab.Entry
+	// Concurrency is the value of the annotation cep:concurrency, allow when the annotation is missing
+	Concurrency scheduler.Policy
+	// CatchUp is the value of the annotations cep:catchup, cep:catchup-limit and cep:catchup-deadline,
+	// none when the annotations are missing
+	CatchUp scheduler.CatchUp
+	// Timeout is the value of the annotation cep:timeout, the process group of an attempt is killed when
+	// it lasts longer. There is no timeout when it is 0
+	Timeout time.Duration
+	// Retries is the value of the annotation cep:retries, the number of times a failed run is tried again
+	Retries int
+	// Backoff is the value of the annotation cep:backoff, the delay before the first retry that doubles at
+	// every retry up to BackoffMax, the value of cep:backoff-max
+	Backoff    time.Duration
+	BackoffMax time.Duration
+	// Jitter is the value of the annotation cep:jitter, the delays are randomly shortened by up to this
+	// fraction of them so that the retries of more jobs do not happen at the same time
+	Jitter float64
+	// After is the value of the annotation cep:after, the names of the jobs separated by a comma that must
+	// succeed before the job runs. A job with dependencies does not run at the times of its schedule
+	After []string
+	// Window is the value of the annotation cep:window, when it is set a job with dependencies runs only
+	// within this time after a run of its schedule, at most once for each window
+	Window time.Duration
+
+	// id is the ID of the entry of the job in the scheduler
+	id int
+}
+
+// NewJob returns the job of a crontab entry with the environment settings of its file
+func NewJob(e *crontab.Entry, env []string) (*Job, error) {
+	j, err := newJob(e, env)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("line %d: %s", e.Line, err.Error()))
+	}
+	return j, nil
+}
+
+// newJob returns the job of a crontab entry or the error of its annotations
+func newJob(e *crontab.Entry, env []string) (*Job, error) {
+	j := &Job{
+		Name:       jobName(e),
+		Command:    e.Command,
+		Env:        env,
+		Entry:      e,
+		Backoff:    defaultBackoff,
+		BackoffMax: defaultBackoffMax,
+		Jitter:     defaultJitter,
+	}
+	a := e.Annotations
+	if key := unknownKey(a); key != "" {
+		return nil, errors.New(fmt.Sprintf("Unknown annotation 'cep:%s'", key))
+	}
+	var err error
+	if policy, ok := a["concurrency"]; ok {
+		if j.Concurrency, err = scheduler.ParsePolicy(policy); err != nil {
+			return nil, err
+		}
+	}
+	if j.CatchUp, err = catchUp(a); err != nil {
+		return nil, err
+	}
+	if err := duration(a, "timeout", &j.Timeout); err != nil {
+		return nil, err
+	}
+	if err := duration(a, "backoff", &j.Backoff); err != nil {
+		return nil, err
+	}
+	if err := duration(a, "backoff-max", &j.BackoffMax); err != nil {
+		return nil, err
+	}
+	if retries, ok := a["retries"]; ok {
+		j.Retries, err = strconv.Atoi(retries)
+		if err != nil || j.Retries < 0 {
+			return nil, errors.New(fmt.Sprintf("Invalid number of retries '%s'", retries))
+		}
+	}
+	if after := a["after"]; after != "" {
+		for _, name := range strings.Split(after, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				j.After = append(j.After, name)
+			}
+		}
+	}
+	if err := duration(a, "window", &j.Window); err != nil {
+		return nil, err
+	}
+	if j.Window > 0 && len(j.After) == 0 {
+		return nil, errors.New("The window needs the jobs to run after, e.g. cep:after=extract")
+	}
+	if jitter, ok := a["jitter"]; ok {
+		j.Jitter, err = strconv.ParseFloat(jitter, 64)
+		if err != nil || j.Jitter < 0 || j.Jitter > 1 {
+			return nil, errors.New(fmt.Sprintf("Invalid jitter '%s', expected a fraction from 0 to 1", jitter))
+		}
+	}
+	return j, nil
+}
+
+// unknownKey returns the first key, in alphabetical order, of the options that is not a known annotation
+// or an empty string if all the keys are known
+func unknownKey(options map[string]string) string {
+	unknown := []string{}
+	for k := range options {
+		if !annotationKeys[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) == 0 {
+		return ""
+	}
+	sort.Strings(unknown)
+	return unknown[0]
+}
+
+// jobName returns the value of the annotation cep:name of the entry or its command
+func jobName(e *crontab.Entry) string {
+	if name := e.Annotations["name"]; name != "" {
+		return name
+	}
+	return e.Command
+}
+
+// duration sets the value of a duration annotation, the value is not changed when the annotation is missing
+func duration(annotations map[string]string, key string, value *time.Duration) error {
+	s, ok := annotations[key]
+	if !ok {
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return errors.New(fmt.Sprintf("Invalid %s '%s', expected a duration, e.g. 10m", key, s))
+	}
+	*value = d
+	return nil
+}
+
+// catchUp returns the catch-up policy set by the annotations
+func catchUp(annotations map[string]string) (scheduler.CatchUp, error) {
+	c := scheduler.CatchUp{}
+	policy, ok := annotations["catchup"]
+	if !ok {
+		return c, nil
+	}
+	var err error
+	if c.Policy, err = scheduler.ParseCatchUpPolicy(policy); err != nil {
+		return c, err
+	}
+	if limit, ok := annotations["catchup-limit"]; ok {
+		c.Limit, err = strconv.Atoi(limit)
+		if err != nil || c.Limit < 1 {
+			return c, errors.New(fmt.Sprintf("Invalid catch-up limit '%s', expected a number of runs greater than 0", limit))
+		}
+	}
+	if c.Policy == scheduler.CatchUpDeadline {
+		deadline := annotations["catchup-deadline"]
+		c.Deadline, err = time.ParseDuration(deadline)
+		if err != nil || c.Deadline <= 0 {
+			return c, errors.New(fmt.Sprintf("Invalid catch-up deadline '%s', the deadline policy needs a duration, e.g. cep:catchup-deadline=2h", deadline))
+		}
+	}
+	return c, nil
+}
+
+// Delay returns the delay before the retry, starting from 1, with a random jitter
+func (j *Job) Delay(retry int) time.Duration {
+	return j.delay(retry, rand.Float64())
+}
+
+// delay returns the delay before the retry with the random number r in [0, 1) for the jitter
+func (j *Job) delay(retry int, r float64) time.Duration {
+	d := j.Backoff
+	for i := 1; i < retry && d < j.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > j.BackoffMax {
+		d = j.BackoffMax
+	}
+	return d - time.Duration(j.Jitter*r*float64(d))
+}
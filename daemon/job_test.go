@@ -0,0 +1,198 @@
+package daemon
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/clock"
+	"github.com/reclaro/cep/crontab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobOptions(t *testing.T) {
+	tests := []struct {
+		annotations string
+		check       func(j *Job)
+		err         string
+	}{
+		{"", func(j *Job) {
+			assert.Equal(t, time.Duration(0), j.Timeout)
+			assert.Equal(t, 0, j.Retries)
+			assert.Equal(t, defaultBackoff, j.Backoff)
+			assert.Equal(t, defaultBackoffMax, j.BackoffMax)
+			assert.Equal(t, defaultJitter, j.Jitter)
+		}, ""},
+		{"cep:timeout=90s cep:retries=3 cep:backoff=1m cep:backoff-max=1h cep:jitter=0.5", func(j *Job) {
+			assert.Equal(t, 90*time.Second, j.Timeout)
+			assert.Equal(t, 3, j.Retries)
+			assert.Equal(t, time.Minute, j.Backoff)
+			assert.Equal(t, time.Hour, j.BackoffMax)
+			assert.Equal(t, 0.5, j.Jitter)
+		}, ""},
+		{"cep:timeout=soon", nil, "line 2: Invalid timeout 'soon', expected a duration, e.g. 10m"},
+		{"cep:backoff=-1s", nil, "line 2: Invalid backoff '-1s', expected a duration, e.g. 10m"},
+		{"cep:retries=many", nil, "line 2: Invalid number of retries 'many'"},
+		{"cep:jitter=2", nil, "line 2: Invalid jitter '2', expected a fraction from 0 to 1"},
+		{"cep:retires=3", nil, "line 2: Unknown annotation 'cep:retires'"},
+		{"cep:duration=20m cep:lock=db", func(j *Job) {
+			assert.Equal(t, 0, j.Retries)
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.annotations, func(t *testing.T) {
+			f := parse(t, "# "+tt.annotations+"\n0 3 * * * backup\n")
+			j, err := NewJob(f.Entries[0], nil)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.Nil(t, err)
+			tt.check(j)
+		})
+	}
+}
+
+func TestDelay(t *testing.T) {
+	j := &Job{Backoff: 10 * time.Second, BackoffMax: time.Minute, Jitter: 0.5}
+	tests := []struct {
+		retry int
+		r     float64
+		delay time.Duration
+	}{
+		{1, 0, 10 * time.Second},
+		{2, 0, 20 * time.Second},
+		{3, 0, 40 * time.Second},
+		{4, 0, time.Minute},
+		{100, 0, time.Minute},
+		{1, 0.5, 7500 * time.Millisecond},
+		{4, 0.99, 30300 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.delay, j.delay(tt.retry, tt.r), "retry %d", tt.retry)
+	}
+	for i := 0; i < 100; i++ {
+		d := j.Delay(2)
+		assert.True(t, d > 10*time.Second && d <= 20*time.Second, "unexpected delay %s", d)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	f := parse(t, `# cep:name=backup cep:retries=1
+0 3 * * * /bin/backup
+0 4 * * * /bin/report
+`)
+	c := Config{
+		"*":      {"timeout": "1h", "retries": "5"},
+		"backup": {"retries": "2", "backoff": "1m"},
+	}
+	require.Nil(t, c.Apply([]*crontab.File{f}))
+	assert.Equal(t, map[string]string{"name": "backup", "timeout": "1h", "retries": "1", "backoff": "1m"}, f.Entries[0].Annotations)
+	assert.Equal(t, map[string]string{"timeout": "1h", "retries": "5"}, f.Entries[1].Annotations)
+}
+
+func TestConfigInvalid(t *testing.T) {
+	tests := []struct {
+		config Config
+		err    string
+	}{
+		{Config{"*": {"retires": "3"}}, "Unknown option 'retires' of the job '*' in the configuration"},
+		{Config{"backup": {"timeout": "1h", "time-out": "1h"}}, "Unknown option 'time-out' of the job 'backup' in the configuration"},
+		{Config{"bakup": {"retries": "3"}}, "The configuration has the job 'bakup' that is not in the crontab files"},
+	}
+	for _, tt := range tests {
+		f := parse(t, "# cep:name=backup\n0 3 * * * /bin/backup\n")
+		assert.EqualError(t, tt.config.Apply([]*crontab.File{f}), tt.err)
+		// the files are not changed
+		assert.Equal(t, map[string]string{"name": "backup"}, f.Entries[0].Annotations)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	f := parse(t, "# cep:name=flaky cep:retries=2 cep:backoff=1m cep:jitter=0\n* * * * * echo failing; exit 1\n")
+	out := &syncBuffer{}
+	d, err := New([]*crontab.File{f}, time.UTC, out)
+	require.Nil(t, err)
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	d.Clock = fake
+
+	results := make(chan Result)
+	go func() {
+		results <- d.Run(context.Background(), d.Jobs()[0])
+	}()
+	fake.BlockUntil(1)
+	assert.Equal(t, []time.Time{start.Add(time.Minute)}, fake.Timers())
+	fake.Advance(time.Minute)
+	fake.BlockUntil(1)
+	assert.Equal(t, []time.Time{start.Add(3 * time.Minute)}, fake.Timers())
+	fake.Advance(2 * time.Minute)
+	res := <-results
+	assert.Equal(t, 3, res.Attempt)
+	assert.Equal(t, 1, res.ExitCode)
+	assert.NotNil(t, res.Err)
+
+	js, err := d.Store.Get("flaky")
+	require.Nil(t, err)
+	require.Len(t, js.History, 3)
+	for i, a := range js.History {
+		assert.Equal(t, i+1, a.Attempt)
+		assert.Equal(t, "The command exited with code 1", a.Error)
+	}
+	assert.Equal(t, start.Add(3*time.Minute), js.Start)
+
+	retries := []string{}
+	for _, e := range out.events(t) {
+		if e.Message == "job retry scheduled" {
+			retries = append(retries, e.RetryIn)
+		}
+	}
+	assert.Equal(t, []string{"1m0s", "2m0s"}, retries)
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cep")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	// the first attempt creates the marker and it fails, the second one finds it
+	marker := filepath.Join(dir, "marker")
+	f := parse(t, "# cep:name=second cep:retries=3 cep:backoff=1ms\n* * * * * test -e "+marker+" || { touch "+marker+"; exit 1; }\n")
+	d, err := New([]*crontab.File{f}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	res := d.Run(context.Background(), d.Jobs()[0])
+	assert.Nil(t, res.Err)
+	assert.Equal(t, 2, res.Attempt)
+}
+
+func TestTimeout(t *testing.T) {
+	f := parse(t, "# cep:name=hang cep:timeout=1h\n* * * * * sleep 5\n")
+	d, err := New([]*crontab.File{f}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	d.Clock = fake
+
+	results := make(chan Result)
+	begin := time.Now()
+	go func() {
+		results <- d.Run(context.Background(), d.Jobs()[0])
+	}()
+	// the timeout is a timer of the clock of the daemon
+	fake.BlockUntil(1)
+	assert.Equal(t, []time.Time{start.Add(time.Hour)}, fake.Timers())
+	fake.Advance(time.Hour)
+	res := <-results
+	assert.True(t, res.TimedOut)
+	assert.EqualError(t, res.Err, "The command timed out after 1h0m0s")
+	assert.Equal(t, time.Hour, res.Duration)
+	assert.True(t, time.Since(begin) < 5*time.Second)
+	assert.Empty(t, fake.Timers())
+
+	js, _ := d.Store.Get("hang")
+	require.Len(t, js.History, 1)
+	assert.True(t, js.History[0].TimedOut)
+}
@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Event is a structured log record of the daemon, written as a JSON object on a single line
+type Event struct {
+	Time      time.Time  `json:"time"`
+	Level     string     `json:"level"`
+	Message   string     `json:"msg"`
+	Job       string     `json:"job,omitempty"`
+	Command   string     `json:"command,omitempty"`
+	Next      *time.Time `json:"next,omitempty"`
+	Scheduled *time.Time `json:"scheduled,omitempty"`
+	Start     *time.Time `json:"start,omitempty"`
+	Attempt   int        `json:"attempt,omitempty"`
+	RetryIn   string     `json:"retry_in,omitempty"`
+	Duration  string     `json:"duration,omitempty"`
+	ExitCode  *int       `json:"exit_code,omitempty"`
+	Stdout    string     `json:"stdout,omitempty"`
+	Stderr    string     `json:"stderr,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Logger writes the events to a writer, one JSON object per line. It is safe to use from more goroutines
+type Logger struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewLogger returns a logger that writes to out
+func NewLogger(out io.Writer) *Logger {
+	return &Logger{out: out}
+}
+
+// Log writes the event, the time of the event is set when it is zero
+func (l *Logger) Log(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.Level == "" {
+		e.Level = "info"
+	}
+	// the commands often contain > and &, they are not escaped as the log is not HTML
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(e); err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(b.Bytes())
+}
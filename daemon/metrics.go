@@ -0,0 +1,185 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// durationBuckets are the upper bounds in seconds of the buckets of the histogram of the durations, from
+// the short commands to the jobs of an hour
+var durationBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 600, 1800, 3600}
+
+// jobMetrics are the metrics of a job
+type jobMetrics struct {
+	runs        int
+	failures    int
+	lastSuccess time.Time
+	// buckets counts the attempts of each bucket, not cumulatively, the last one is +Inf
+	buckets  []int
+	sum      float64
+	attempts int
+}
+
+// metrics collects the metrics of the jobs of the daemon
+type metrics struct {
+	mu   sync.Mutex
+	jobs map[string]*jobMetrics
+}
+
+func newMetrics() *metrics {
+	return &metrics{jobs: map[string]*jobMetrics{}}
+}
+
+// job returns the metrics of a job, it must be called holding the lock
+func (m *metrics) job(name string) *jobMetrics {
+	jm, ok := m.jobs[name]
+	if !ok {
+		jm = &jobMetrics{buckets: make([]int, len(durationBuckets)+1)}
+		m.jobs[name] = jm
+	}
+	return jm
+}
+
+// prune removes the metrics of the jobs that are not in names
+func (m *metrics) prune(names map[string]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for name := range m.jobs {
+		if !names[name] {
+			delete(m.jobs, name)
+		}
+	}
+}
+
+// observeAttempt adds the duration of an attempt to the histogram
+func (m *metrics) observeAttempt(name string, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jm := m.job(name)
+	seconds := d.Seconds()
+	i := sort.SearchFloat64s(durationBuckets, seconds)
+	jm.buckets[i]++
+	jm.sum += seconds
+	jm.attempts++
+}
+
+// observeRun counts a run after its retries, end is the end of its last attempt
+func (m *metrics) observeRun(name string, res Result, end time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	jm := m.job(name)
+	jm.runs++
+	if res.Err != nil {
+		jm.failures++
+		return
+	}
+	jm.lastSuccess = end
+}
+
+// MetricsHandler returns the handler that serves the metrics of the jobs in the Prometheus text format:
+// the runs and the failed runs after their retries, the time of the last successful run, the histogram of
+// the durations of the attempts and the time of the next run
+func (d *Daemon) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		d.WriteMetrics(w)
+	})
+}
+
+// WriteMetrics writes the metrics of the jobs in the Prometheus text format, only the jobs of the daemon are
+// written, not the jobs removed by a reload
+func (d *Daemon) WriteMetrics(w io.Writer) {
+	next := map[string]time.Time{}
+	for _, e := range d.scheduler.Entries() {
+		if n, ok := next[e.Name]; !e.Next.IsZero() && (!ok || e.Next.Before(n)) {
+			next[e.Name] = e.Next
+		}
+	}
+
+	jobs := d.Jobs()
+	m := d.metrics
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := []string{}
+	seen := map[string]bool{}
+	for _, j := range jobs {
+		// more jobs can have the same name, e.g. the same command
+		if !seen[j.Name] {
+			seen[j.Name] = true
+			names = append(names, j.Name)
+		}
+		m.job(j.Name)
+	}
+	sort.Strings(names)
+
+	header(w, "cep_job_runs_total", "counter", "Number of runs of the job, a run and its retries count once.")
+	for _, name := range names {
+		sample(w, "cep_job_runs_total", label(name), float64(m.jobs[name].runs))
+	}
+	header(w, "cep_job_failures_total", "counter", "Number of runs of the job that failed after all the retries.")
+	for _, name := range names {
+		sample(w, "cep_job_failures_total", label(name), float64(m.jobs[name].failures))
+	}
+	header(w, "cep_job_last_success_timestamp_seconds", "gauge", "Time of the end of the last successful run of the job.")
+	for _, name := range names {
+		if t := m.jobs[name].lastSuccess; !t.IsZero() {
+			sample(w, "cep_job_last_success_timestamp_seconds", label(name), seconds(t))
+		}
+	}
+	header(w, "cep_job_duration_seconds", "histogram", "Duration of the attempts of the job.")
+	for _, name := range names {
+		jm := m.jobs[name]
+		count := 0
+		for i, b := range durationBuckets {
+			count += jm.buckets[i]
+			sample(w, "cep_job_duration_seconds_bucket", label(name)+`,le="`+formatFloat(b)+`"`, float64(count))
+		}
+		sample(w, "cep_job_duration_seconds_bucket", label(name)+`,le="+Inf"`, float64(jm.attempts))
+		sample(w, "cep_job_duration_seconds_sum", label(name), jm.sum)
+		sample(w, "cep_job_duration_seconds_count", label(name), float64(jm.attempts))
+	}
+	header(w, "cep_job_next_run_timestamp_seconds", "gauge", "Time of the next scheduled run of the job.")
+	for _, name := range names {
+		if t, ok := next[name]; ok {
+			sample(w, "cep_job_next_run_timestamp_seconds", label(name), seconds(t))
+		}
+	}
+}
+
+// header writes the help and the type of a metric
+func header(w io.Writer, name string, kind string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a sample of a metric with its labels
+func sample(w io.Writer, name string, labels string, value float64) {
+	fmt.Fprintf(w, "%s{%s} %s\n", name, labels, formatFloat(value))
+}
+
+// jobLabel is the label with the name of the job, job is the label Prometheus sets to the scraped target
+const jobLabel = "cron_job"
+
+// label returns the label of the job, the backslashes, the double quotes and the new lines of the name are
+// escaped
+func label(name string) string {
+	return jobLabel + `="` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(name) + `"`
+}
+
+// seconds returns the Unix time in seconds with the fraction of the second
+func seconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1e9
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
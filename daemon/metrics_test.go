@@ -0,0 +1,100 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/clock"
+	"github.com/reclaro/cep/crontab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	f := parse(t, `# cep:name=ok
+*/5 * * * * true
+# cep:name=fail cep:retries=1 cep:backoff=1ms
+0 * * * * exit 1
+0 0 1 1 * echo "new \ year"
+`)
+	d, err := New([]*crontab.File{f}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	start := time.Date(2021, 1, 1, 0, 1, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	d.Clock = fake
+	d.Run(context.Background(), d.Jobs()[0])
+	d.Run(context.Background(), d.Jobs()[0])
+	done := make(chan struct{})
+	go func() {
+		d.Run(context.Background(), d.Jobs()[1])
+		close(done)
+	}()
+	// the retry waits for the backoff
+	fake.BlockUntil(1)
+	fake.Advance(time.Millisecond)
+	<-done
+	require.Nil(t, d.Start(context.Background()))
+	defer d.Stop(context.Background())
+
+	rec := httptest.NewRecorder()
+	d.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+
+	for _, line := range []string{
+		`# TYPE cep_job_runs_total counter`,
+		`cep_job_runs_total{cron_job="ok"} 2`,
+		`cep_job_runs_total{cron_job="fail"} 1`,
+		`cep_job_runs_total{cron_job="echo \"new \\ year\""} 0`,
+		`cep_job_failures_total{cron_job="ok"} 0`,
+		`cep_job_failures_total{cron_job="fail"} 1`,
+		`cep_job_last_success_timestamp_seconds{cron_job="ok"} 1.60945926e+09`,
+		`# TYPE cep_job_duration_seconds histogram`,
+		`cep_job_duration_seconds_bucket{cron_job="fail",le="0.1"} 2`,
+		`cep_job_duration_seconds_bucket{cron_job="fail",le="+Inf"} 2`,
+		`cep_job_duration_seconds_count{cron_job="fail"} 2`,
+		`cep_job_duration_seconds_sum{cron_job="ok"} 0`,
+		`cep_job_next_run_timestamp_seconds{cron_job="ok"} 1.6094595e+09`,
+		`cep_job_next_run_timestamp_seconds{cron_job="fail"} 1.6094628e+09`,
+		`cep_job_next_run_timestamp_seconds{cron_job="echo \"new \\ year\""} 1.6409952e+09`,
+	} {
+		assert.Contains(t, body, line+"\n")
+	}
+	assert.NotContains(t, body, `cep_job_last_success_timestamp_seconds{cron_job="fail"}`)
+}
+
+func TestHistogram(t *testing.T) {
+	d, err := New([]*crontab.File{parse(t, "# cep:name=job\n0 * * * * true\n")}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	for _, s := range []float64{0.05, 1, 2, 7200} {
+		d.metrics.observeAttempt("job", time.Duration(s*float64(time.Second)))
+	}
+	var b bytes.Buffer
+	d.WriteMetrics(&b)
+	buckets := []string{}
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.HasPrefix(line, "cep_job_duration_seconds") {
+			buckets = append(buckets, line)
+		}
+	}
+	assert.Equal(t, []string{
+		`cep_job_duration_seconds_bucket{cron_job="job",le="0.1"} 1`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="0.5"} 1`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="1"} 2`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="5"} 3`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="10"} 3`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="30"} 3`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="60"} 3`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="300"} 3`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="600"} 3`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="1800"} 3`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="3600"} 3`,
+		`cep_job_duration_seconds_bucket{cron_job="job",le="+Inf"} 4`,
+		`cep_job_duration_seconds_sum{cron_job="job"} 7203.05`,
+		`cep_job_duration_seconds_count{cron_job="job"} 4`,
+	}, buckets)
+}
@@ -0,0 +1,11 @@
+package daemon
+
+import "os/exec"
+
+// setProcessGroup does nothing, Windows has no process groups
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the process of a started command
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
@@ -0,0 +1,185 @@
+package daemon
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/crontab"
+)
+
+// LoadFiles reads the crontab files and it applies the sidecar configuration when its path is not empty
+func LoadFiles(paths []string, configPath string) ([]*crontab.File, error) {
+	files := []*crontab.File{}
+	for _, path := range paths {
+		f, err := crontab.Load(path)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("%s: %s", path, err.Error()))
+		}
+		files = append(files, f)
+	}
+	if configPath != "" {
+		c, err := LoadConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.Apply(files); err != nil {
+			return nil, errors.New(fmt.Sprintf("%s: %s", configPath, err.Error()))
+		}
+	}
+	return files, nil
+}
+
+/*
+Reload replaces the jobs of the daemon with the jobs of the crontab files. The jobs that did not change keep
+their schedule and their running runs, the removed jobs are not scheduled any more but their running runs
+go on, the changed jobs are removed and added again.
+If a job of the files is invalid, the jobs of the daemon do not change and the error is returned.
+*/
+func (d *Daemon) Reload(files []*crontab.File) error {
+	jobs, err := newJobs(files)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	old := map[string][]*Job{}
+	for _, j := range d.jobs {
+		old[j.key()] = append(old[j.key()], j)
+	}
+	added := []*Job{}
+	for i, j := range jobs {
+		k := j.key()
+		if same := old[k]; len(same) > 0 {
+			jobs[i] = same[0]
+			old[k] = same[1:]
+			continue
+		}
+		added = append(added, j)
+	}
+	removed := 0
+	for _, same := range old {
+		for _, j := range same {
+			if d.started {
+				d.scheduler.Remove(j.id)
+			}
+			delete(d.triggers, j)
+			removed++
+		}
+	}
+	if d.started {
+		for _, j := range added {
+			if err := d.register(j); err != nil {
+				return err
+			}
+		}
+	}
+	d.jobs = jobs
+	names := map[string]bool{}
+	for _, j := range jobs {
+		names[j.Name] = true
+	}
+	d.metrics.prune(names)
+	d.log.Log(Event{Time: d.Clock.Now(), Message: fmt.Sprintf("crontab reloaded, %d jobs added, %d removed and %d unchanged",
+		len(added), removed, len(jobs)-len(added))})
+	if d.started {
+		d.logScheduled(added)
+	}
+	return nil
+}
+
+// key identifies the definition of a job, two jobs with the same key are the same job
+func (j *Job) key() string {
+	annotations := []string{}
+	for k, v := range j.Entry.Annotations {
+		annotations = append(annotations, k+"="+v)
+	}
+	sort.Strings(annotations)
+	return strings.Join([]string{
+		j.Name,
+		strings.Join(j.Entry.Fields, " "),
+		j.Command,
+		strings.Join(j.Env, "\n"),
+		strings.Join(annotations, "\n"),
+	}, "\x00")
+}
+
+// fingerprint identifies the content of a file, the hash is computed only when the modification time or
+// the size change
+type fingerprint struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// fingerprintOf returns the fingerprint of the file, it reuses the hash of the previous fingerprint when
+// the file looks unchanged
+func fingerprintOf(path string, previous fingerprint) (fingerprint, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fingerprint{}, err
+	}
+	fp := fingerprint{modTime: info.ModTime(), size: info.Size()}
+	if fp.modTime.Equal(previous.modTime) && fp.size == previous.size {
+		fp.hash = previous.hash
+		return fp, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fingerprint{}, err
+	}
+	fp.hash = sha256.Sum256(b)
+	return fp, nil
+}
+
+/*
+Watch polls the crontab files and the sidecar configuration every interval until the context is done and it
+reloads the jobs when the content of a file changes. The modification time and the size are checked first and
+the content is hashed only when they change, so that touching a file does not reload it. An invalid file is
+logged and the jobs do not change until the file is fixed.
+*/
+func (d *Daemon) Watch(ctx context.Context, paths []string, configPath string, interval time.Duration) {
+	watched := append([]string{}, paths...)
+	if configPath != "" {
+		watched = append(watched, configPath)
+	}
+	fingerprints := make([]fingerprint, len(watched))
+	for i, path := range watched {
+		fingerprints[i], _ = fingerprintOf(path, fingerprint{})
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.Clock.After(interval):
+		}
+		changed := false
+		for i, path := range watched {
+			fp, err := fingerprintOf(path, fingerprints[i])
+			if err != nil {
+				// a file can be missing for a moment while an editor replaces it
+				continue
+			}
+			if fp.hash != fingerprints[i].hash {
+				changed = true
+			}
+			fingerprints[i] = fp
+		}
+		if !changed {
+			continue
+		}
+		files, err := LoadFiles(paths, configPath)
+		if err == nil {
+			err = d.Reload(files)
+		}
+		if err != nil {
+			d.log.Log(Event{Time: d.Clock.Now(), Level: "error", Message: "crontab reload failed, the previous jobs are kept", Error: err.Error()})
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package daemon
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/clock"
+	"github.com/reclaro/cep/crontab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	out := &syncBuffer{}
+	d, err := New([]*crontab.File{parse(t, `0 * * * * /bin/same
+0 * * * * /bin/changed
+0 * * * * /bin/removed
+`)}, time.UTC, out)
+	require.Nil(t, err)
+	d.Clock = clock.NewFake(time.Date(2021, 1, 1, 0, 0, 30, 0, time.UTC))
+	require.Nil(t, d.Start(context.Background()))
+	defer d.Stop(context.Background())
+	same := d.Jobs()[0]
+
+	err = d.Reload([]*crontab.File{parse(t, `0 * * * * /bin/same
+30 * * * * /bin/changed
+0 * * * * /bin/added
+`)})
+	require.Nil(t, err)
+	jobs := d.Jobs()
+	require.Len(t, jobs, 3)
+	assert.True(t, same == jobs[0], "the unchanged job must be kept")
+
+	next := map[string]time.Time{}
+	for _, e := range d.scheduler.Entries() {
+		next[e.Name] = e.Next
+	}
+	assert.Equal(t, map[string]time.Time{
+		"/bin/same":    time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC),
+		"/bin/changed": time.Date(2021, 1, 1, 0, 30, 0, 0, time.UTC),
+		"/bin/added":   time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC),
+	}, next)
+
+	messages := []string{}
+	for _, e := range out.events(t) {
+		if strings.HasPrefix(e.Message, "crontab reloaded") {
+			messages = append(messages, e.Message)
+		}
+	}
+	assert.Equal(t, []string{"crontab reloaded, 2 jobs added, 2 removed and 1 unchanged"}, messages)
+}
+
+func TestReloadAnnotations(t *testing.T) {
+	d, err := New([]*crontab.File{parse(t, "# cep:retries=1\n0 * * * * /bin/job\n")}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	require.Nil(t, d.Reload([]*crontab.File{parse(t, "# cep:retries=2\n0 * * * * /bin/job\n")}))
+	assert.Equal(t, 2, d.Jobs()[0].Retries)
+}
+
+func TestReloadInvalid(t *testing.T) {
+	d, err := New([]*crontab.File{parse(t, "0 * * * * /bin/job\n")}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	err = d.Reload([]*crontab.File{parse(t, "# cep:retries=many\n0 * * * * /bin/other\n")})
+	assert.EqualError(t, err, "line 2: Invalid number of retries 'many'")
+	require.Len(t, d.Jobs(), 1)
+	assert.Equal(t, "/bin/job", d.Jobs()[0].Name)
+}
+
+func TestReloadMetrics(t *testing.T) {
+	d, err := New([]*crontab.File{parse(t, "# cep:name=kept\n0 * * * * true\n# cep:name=removed\n0 * * * * true\n")}, time.UTC, &syncBuffer{})
+	require.Nil(t, err)
+	for _, j := range d.Jobs() {
+		d.Run(context.Background(), j)
+	}
+	require.Nil(t, d.Reload([]*crontab.File{parse(t, "# cep:name=kept\n0 * * * * true\n")}))
+	assert.NotContains(t, d.metrics.jobs, "removed")
+	// a run of the removed job that was still running when the jobs were reloaded
+	d.metrics.observeRun("removed", Result{}, time.Now())
+
+	var b strings.Builder
+	d.WriteMetrics(&b)
+	assert.Contains(t, b.String(), `cep_job_runs_total{cron_job="kept"} 1`+"\n")
+	assert.NotContains(t, b.String(), `"removed"`)
+}
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cep")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "crontab")
+	require.Nil(t, ioutil.WriteFile(path, []byte("0 * * * * /bin/first\n"), 0644))
+
+	files, err := LoadFiles([]string{path}, "")
+	require.Nil(t, err)
+	out := &syncBuffer{}
+	d, err := New(files, time.UTC, out)
+	require.Nil(t, err)
+	fake := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	d.Clock = fake
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Watch(ctx, []string{path}, "", 10*time.Second)
+
+	// poll waits for the watcher and it checks the files once
+	poll := func() {
+		fake.BlockUntil(1)
+		fake.Advance(10 * time.Second)
+		fake.BlockUntil(1)
+	}
+	names := func() []string {
+		res := []string{}
+		for _, j := range d.Jobs() {
+			res = append(res, j.Name)
+		}
+		return res
+	}
+
+	poll()
+	assert.Equal(t, []string{"/bin/first"}, names())
+
+	require.Nil(t, ioutil.WriteFile(path, []byte("0 * * * * /bin/first\n0 * * * * /bin/second\n"), 0644))
+	poll()
+	assert.Equal(t, []string{"/bin/first", "/bin/second"}, names())
+
+	require.Nil(t, ioutil.WriteFile(path, []byte("0 * * * * /bin/first\n0 * * * /bin/broken\n"), 0644))
+	poll()
+	assert.Equal(t, []string{"/bin/first", "/bin/second"}, names())
+	events := out.events(t)
+	last := events[len(events)-1]
+	assert.Equal(t, "crontab reload failed, the previous jobs are kept", last.Message)
+	assert.Equal(t, "error", last.Level)
+
+	// a step of zero used to hang the parsing of the file
+	require.Nil(t, ioutil.WriteFile(path, []byte("0 * * * * /bin/first\n*/0 * * * * /bin/zero\n"), 0644))
+	poll()
+	assert.Equal(t, []string{"/bin/first", "/bin/second"}, names())
+	events = out.events(t)
+	last = events[len(events)-1]
+	assert.Equal(t, "crontab reload failed, the previous jobs are kept", last.Message)
+	assert.Contains(t, last.Error, "Invalid step value '0'")
+
+	require.Nil(t, os.Remove(path))
+	poll()
+	assert.Equal(t, []string{"/bin/first", "/bin/second"}, names())
+
+	require.Nil(t, ioutil.WriteFile(path, []byte("0 * * * * /bin/third\n"), 0644))
+	poll()
+	assert.Equal(t, []string{"/bin/third"}, names())
+}
+
+func TestFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cep")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "crontab")
+	require.Nil(t, ioutil.WriteFile(path, []byte("0 * * * * /bin/job\n"), 0644))
+	fp, err := fingerprintOf(path, fingerprint{})
+	require.Nil(t, err)
+
+	// touching the file changes the modification time but not the hash
+	later := fp.modTime.Add(time.Minute)
+	require.Nil(t, os.Chtimes(path, later, later))
+	touched, err := fingerprintOf(path, fp)
+	require.Nil(t, err)
+	assert.Equal(t, fp.hash, touched.hash)
+	assert.True(t, later.Equal(touched.modTime))
+
+	_, err = fingerprintOf(filepath.Join(dir, "missing"), fp)
+	assert.NotNil(t, err)
+}
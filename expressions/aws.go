@@ -0,0 +1,109 @@
+package expressions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/utils"
+)
+
+// AWSSyntax is an expression holder for the schedule expressions of Amazon EventBridge: cron expressions
+// with 6 fields separated by white spaces in the order minute, hour, day of month, month, day of week and
+// year, without a command, and rate expressions. One of the day fields of a cron expression must be ?.
+// The expressions can be written as cron(...) and rate(...) as in EventBridge or without the cron(...).
+type AWSSyntax struct {
+	input                string
+	cronElements         *CronElements
+	regExpTokenValidator *regexp.Regexp
+}
+
+// awsRate is the syntax of a rate expression, e.g. rate(5 minutes)
+var awsRate = regexp.MustCompile(`^rate\(([0-9]+) (minutes?|hours?|days?)\)$`)
+
+/*
+NewAWSSyntax implements the Holder interface for the EventBridge schedule expressions.
+The fields of the cron expressions are as in Quartz: the days of the week are numbered from 1 (Sunday)
+to 7 (Saturday) and the day fields accept L, W and #.
+The rate expressions are rate(value unit) where the unit is minute, hour or day, singular when the value
+is 1 and plural otherwise.
+*/
+func NewAWSSyntax(input string) (Holder, error) {
+	as := &AWSSyntax{
+		input:                input,
+		regExpTokenValidator: regexp.MustCompile(`^[0-9]+$|^\*$|^[0-9]+\-[0-9]+$|^\*\/[0-9]+$|^[0-9]+\/[0-9]+$|^[0-9]+\-[0-9]+\/[0-9]+$`),
+	}
+	return as, nil
+}
+
+// ValidateExpression receives an input string and return an error if it is not a valid EventBridge expression
+func (as *AWSSyntax) ValidateExpression(input string) error {
+	as.input = input
+	as.cronElements = nil
+	_, err := as.Elements()
+	return err
+}
+
+// Elements return the fields of the expression or error if the expression is invalid
+func (as *AWSSyntax) Elements() (*CronElements, error) {
+	if as.cronElements != nil {
+		return as.cronElements, nil
+	}
+	input := strings.TrimSpace(as.input)
+	if strings.HasPrefix(input, "rate(") {
+		ce, err := as.rate(input)
+		if err != nil {
+			return nil, err
+		}
+		as.cronElements = ce
+		return ce, nil
+	}
+	if strings.HasPrefix(input, "cron(") && strings.HasSuffix(input, ")") {
+		input = input[len("cron(") : len(input)-1]
+	}
+
+	tokens := strings.Fields(input)
+	if len(tokens) != 6 {
+		return nil, errors.New(fmt.Sprintf("Number of fields incorrect for AWS, found %d and expected 6", len(tokens)))
+	}
+	for _, i := range []int{0, 1, 5} {
+		if err := validateList(as.regExpTokenValidator, tokens[i], as.input); err != nil {
+			return nil, err
+		}
+	}
+	month := utils.StringToNumber(tokens[3], monthNames)
+	if err := validateList(as.regExpTokenValidator, month, as.input); err != nil {
+		return nil, err
+	}
+	dom, dow, err := quartzDays(as.regExpTokenValidator, tokens[2], tokens[4], as.input)
+	if err != nil {
+		return nil, err
+	}
+	as.cronElements = &CronElements{
+		Minute:        tokens[0],
+		Hour:          tokens[1],
+		DayMonth:      dom,
+		Month:         month,
+		DayWeek:       dow,
+		Year:          tokens[5],
+		MatchBothDays: true,
+	}
+	return as.cronElements, nil
+}
+
+// rate returns the interval of a rate expression
+func (as *AWSSyntax) rate(input string) (*CronElements, error) {
+	m := awsRate.FindStringSubmatch(input)
+	if m == nil {
+		return nil, errors.New(fmt.Sprintf("Invalid rate expression '%s'", as.input))
+	}
+	value, _ := strconv.Atoi(m[1])
+	if value == 0 || (value == 1) == strings.HasSuffix(m[2], "s") {
+		return nil, errors.New(fmt.Sprintf("Invalid rate expression '%s', the unit must be singular only for the value 1", as.input))
+	}
+	unit := map[string]time.Duration{"minute": time.Minute, "hour": time.Hour, "day": 24 * time.Hour}[strings.TrimSuffix(m[2], "s")]
+	return &CronElements{Every: (time.Duration(value) * unit).String()}, nil
+}
@@ -0,0 +1,50 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSElements(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected *CronElements
+	}{
+		{"cron", "cron(0 9 ? * MON-FRI *)",
+			&CronElements{Minute: "0", Hour: "9", DayMonth: "*", Month: "*", DayWeek: "1,2,3,4,5", Year: "*", MatchBothDays: true}},
+		{"without cron", "15 12 L * ? 2030",
+			&CronElements{Minute: "15", Hour: "12", DayMonth: "L", Month: "*", DayWeek: "*", Year: "2030", MatchBothDays: true}},
+		{"rate", "rate(5 minutes)", &CronElements{Every: "5m0s"}},
+		{"rate of one day", "rate(1 day)", &CronElements{Every: "24h0m0s"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewAWSSyntax(tc.input)
+			require.Nil(t, err)
+			actual, err := h.Elements()
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestAWSInvalid(t *testing.T) {
+	tcs := []string{
+		"cron(0 9 * * MON-FRI *)",
+		"cron(0 9 ? * MON-FRI)",
+		"rate(1 minutes)",
+		"rate(5 minute)",
+		"rate(0 hours)",
+		"rate(5 seconds)",
+	}
+	for _, input := range tcs {
+		t.Run(input, func(t *testing.T) {
+			h, err := NewAWSSyntax(input)
+			require.Nil(t, err)
+			assert.NotNil(t, h.ValidateExpression(input))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package expressions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/reclaro/cep/utils"
+)
+
+// AzureSyntax is an expression holder for the NCRONTAB expressions of the Azure Functions timer triggers:
+// 6 fields separated by white spaces in the order second, minute, hour, day of month, month and day of
+// week, without a command. A day must match both the day of the month and the day of the week.
+type AzureSyntax struct {
+	input                string
+	cronElements         *CronElements
+	regExpTokenValidator *regexp.Regexp
+}
+
+/*
+NewAzureSyntax implements the Holder interface for the NCRONTAB expressions.
+Each field can be one of the following:
+int | int-int | * | * /int | int/int | int-int/int
+and the fields can be lists of them separated by a comma. The months accept also JAN-DEC and the days of
+the week SUN-SAT in any case.
+*/
+func NewAzureSyntax(input string) (Holder, error) {
+	as := &AzureSyntax{
+		input:                input,
+		regExpTokenValidator: regexp.MustCompile(`^[0-9]+$|^\*$|^[0-9]+\-[0-9]+$|^\*\/[0-9]+$|^[0-9]+\/[0-9]+$|^[0-9]+\-[0-9]+\/[0-9]+$`),
+	}
+	return as, nil
+}
+
+// ValidateExpression receives an input string and return an error if it is not a valid NCRONTAB expression
+func (as *AzureSyntax) ValidateExpression(input string) error {
+	as.input = input
+	as.cronElements = nil
+	_, err := as.Elements()
+	return err
+}
+
+// Elements return the fields of the expression or error if the expression is invalid
+func (as *AzureSyntax) Elements() (*CronElements, error) {
+	if as.cronElements != nil {
+		return as.cronElements, nil
+	}
+	tokens := strings.Fields(as.input)
+	if len(tokens) != 6 {
+		return nil, errors.New(fmt.Sprintf("Number of fields incorrect for NCRONTAB, found %d and expected 6", len(tokens)))
+	}
+	tokens[4] = utils.StringToNumber(tokens[4], monthNames)
+	tokens[5] = utils.StringToNumber(tokens[5], dayNames)
+	for _, t := range tokens {
+		if err := validateList(as.regExpTokenValidator, t, as.input); err != nil {
+			return nil, err
+		}
+	}
+	as.cronElements = &CronElements{
+		Second:        tokens[0],
+		Minute:        tokens[1],
+		Hour:          tokens[2],
+		DayMonth:      tokens[3],
+		Month:         tokens[4],
+		DayWeek:       tokens[5],
+		MatchBothDays: true,
+	}
+	return as.cronElements, nil
+}
@@ -0,0 +1,34 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureElements(t *testing.T) {
+	h, err := NewAzureSyntax("0 */5 9-17 * Jan-Mar Mon-Fri")
+	require.Nil(t, err)
+	actual, err := h.Elements()
+	require.Nil(t, err)
+	assert.Equal(t, &CronElements{Second: "0", Minute: "*/5", Hour: "9-17", DayMonth: "*", Month: "1-3", DayWeek: "1-5",
+		MatchBothDays: true}, actual)
+}
+
+func TestAzureInvalid(t *testing.T) {
+	tcs := []string{
+		"*/5 * * * *",
+		"0 */5 * * * * /bin/ls",
+		"0 0 9 ? * *",
+		"0 0 9 L * *",
+		"00:05:00",
+	}
+	for _, input := range tcs {
+		t.Run(input, func(t *testing.T) {
+			h, err := NewAzureSyntax(input)
+			require.Nil(t, err)
+			assert.NotNil(t, h.ValidateExpression(input))
+		})
+	}
+}
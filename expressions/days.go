@@ -0,0 +1,74 @@
+package expressions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// dayWeekToken is a value, a range or a step in a day of the week field where the days are numbers
+var dayWeekToken = regexp.MustCompile(`^(\*|[0-9]+)(?:-([0-9]+))?(?:/([0-9]+))?$`)
+
+// expandDaysOfWeek converts a day of the week field of a syntax where the week goes from 1 to 7 to the
+// days from 0 (Sunday) to 6 used by the parsers. The ranges and the steps are expanded to a list of days
+// because the conversion can move the days to the other end of the week, e.g. in Spring */2 is Monday,
+// Wednesday, Friday and Sunday. lowest is the lowest day accepted by the syntax and weekday converts a day
+// of the syntax to the day used by the parsers, the input is the whole expression used in the error message.
+func expandDaysOfWeek(field string, lowest int, weekday func(int) int, input string) (string, error) {
+	if field == "*" {
+		return "*", nil
+	}
+	days := map[int]bool{}
+	for _, token := range strings.Split(field, ",") {
+		m := dayWeekToken.FindStringSubmatch(token)
+		if m == nil || (m[1] == "*" && m[2] != "") {
+			return "", errors.New(fmt.Sprintf("Invalid input string '%s' please check the correct syntax", input))
+		}
+		start, end, step := 1, 7, 1
+		if m[1] != "*" {
+			start, _ = strconv.Atoi(m[1])
+			end = start
+			if m[2] != "" {
+				end, _ = strconv.Atoi(m[2])
+			} else if m[3] != "" {
+				end = 7
+			}
+		}
+		if m[3] != "" {
+			step, _ = strconv.Atoi(m[3])
+		}
+		if start < lowest || start > end || end > 7 || step == 0 {
+			return "", errors.New(fmt.Sprintf("Invalid day of the week '%s'", token))
+		}
+		for d := start; d <= end; d += step {
+			days[weekday(d)] = true
+		}
+	}
+	if len(days) == 7 {
+		return "*", nil
+	}
+	res := []int{}
+	for d := range days {
+		res = append(res, d)
+	}
+	sort.Ints(res)
+	values := []string{}
+	for _, d := range res {
+		values = append(values, strconv.Itoa(d))
+	}
+	return strings.Join(values, ","), nil
+}
+
+// validateList checks the syntax of each part of a comma separated field, the input is the whole
+// expression used in the error message
+func validateList(re *regexp.Regexp, token string, input string) error {
+	for _, str := range strings.Split(token, ",") {
+		if !re.MatchString(str) {
+			return errors.New(fmt.Sprintf("Invalid input string '%s' please check the correct syntax", input))
+		}
+	}
+	return nil
+}
@@ -17,7 +17,12 @@ type Holder interface {
 	ValidateExpression(string) error
 }
 
-// CronElements is the struct for the 6 different fields that are present in a cron expression
+// CronElements is the struct for the 6 different fields that are present in a cron expression.
+// Second is set only by the syntaxes that have a field for the seconds, Every is set instead of the time
+// fields by the syntaxes that support schedules with a constant interval (e.g. @every 1h30m), Year is set
+// only by the syntaxes that have a field for the years.
+// MatchBothDays is set by the syntaxes where a day must match both the day of the month and the day of
+// the week, instead of one of them when both are restricted as in the standard cron
 type CronElements struct {
 	Minute   string
 	Hour     string
@@ -25,6 +30,11 @@ type CronElements struct {
 	Month    string
 	DayWeek  string
 	Command  string
+	Second   string
+	Every    string
+	Year     string
+
+	MatchBothDays bool
 }
 
 // Default represents a default expression holder for the default cron job syntax.
@@ -99,7 +109,7 @@ func (ds *DefaultSyntax) ValidateExpression(input string) error {
 // validateFields check that input string is made by the specific number of fields separated by a
 // specific separator
 func (ds *DefaultSyntax) validateFields(input string) error {
-	tokens := strings.Split(input, ds.separator)
+	tokens := strings.SplitN(input, ds.separator, ds.fields)
 	if len(tokens) != ds.fields {
 		return errors.New(fmt.Sprintf("Number of fields incorrect for %s, found %d and expected %d", ds.name, len(tokens), ds.fields))
 	}
@@ -127,7 +137,9 @@ func (ds *DefaultSyntax) Elements() (*CronElements, error) {
 func (ds *DefaultSyntax) tokenize() error {
 	// Note this split on a single white space, if we want to split on white spaces
 	// we can use a regexp for it regexp.MustCompile(`\S+`) and then re.FindAllString(input, -1)
-	tokens := strings.Split(ds.input, ds.separator)
+	// The command is the last field and it can contain the separator (e.g. arguments), so we
+	// split at most in ds.fields parts
+	tokens := strings.SplitN(ds.input, ds.separator, ds.fields)
 	// We check if it has been passed the strings format for Day of week and month
 	// and we convert it to the integers
 	tokens[3] = utils.StringToNumber(tokens[3], ds.monthsMapper)
@@ -0,0 +1,94 @@
+package expressions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Dialect is a cron syntax that can be selected by name, New returns the holder of an expression in
+// the syntax. Command is true for the syntaxes where the expressions end with a command
+type Dialect struct {
+	Name        string
+	Description string
+	Command     bool
+	New         func(string) (Holder, error)
+}
+
+// Compatibility is the result of the validation of an expression with a dialect, Err is the reason why
+// the dialect rejects the expression
+type Compatibility struct {
+	Dialect Dialect
+	Err     error
+}
+
+// dialects are the registered dialects in the order they are tried
+var dialects = []Dialect{
+	{Name: "vixie", Description: "Vixie cron and crontab files", Command: true, New: NewVixieSyntax},
+	{Name: "kubernetes", Description: "Kubernetes CronJob", New: NewKubernetesSyntax},
+	{Name: "quartz", Description: "Quartz scheduler", New: NewQuartzSyntax},
+	{Name: "aws", Description: "Amazon EventBridge", New: NewAWSSyntax},
+	{Name: "robfig", Description: "robfig/cron v3 with optional seconds", New: NewRobfigSyntax},
+	{Name: "systemd", Description: "systemd timers OnCalendar", New: NewSystemdSyntax},
+	{Name: "azure", Description: "Azure Functions NCRONTAB", New: NewAzureSyntax},
+	{Name: "spring", Description: "Spring @Scheduled", New: NewSpringSyntax},
+}
+
+// Dialects returns the registered dialects
+func Dialects() []Dialect {
+	return append([]Dialect{}, dialects...)
+}
+
+// RegisterDialect adds a dialect to the registry, it replaces the dialect with the same name
+func RegisterDialect(d Dialect) {
+	for i := range dialects {
+		if dialects[i].Name == d.Name {
+			dialects[i] = d
+			return
+		}
+	}
+	dialects = append(dialects, d)
+}
+
+// LookupDialect returns the dialect with the input name or an error if it is not registered
+func LookupDialect(name string) (Dialect, error) {
+	for _, d := range dialects {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return Dialect{}, errors.New(fmt.Sprintf("Unknown dialect '%s'", name))
+}
+
+// placeholder is the command added to the expressions for the dialects that require a command
+const placeholder = "command"
+
+// Compat validates an expression without a command with every registered dialect, a placeholder command
+// is added for the dialects that require it. check is an additional validation of the holder, e.g. the
+// parsing of the values of the fields, it can be nil
+func Compat(input string, check func(Holder) error) []Compatibility {
+	res := []Compatibility{}
+	for _, d := range dialects {
+		expression := input
+		if d.Command {
+			expression += " " + placeholder
+		}
+		h, err := d.New(expression)
+		if err == nil {
+			err = h.ValidateExpression(expression)
+		}
+		// the fields after the time fields are part of the command
+		if err == nil && d.Command {
+			ce, _ := h.Elements()
+			if ce.Command != placeholder {
+				err = errors.New(fmt.Sprintf("Too many fields, '%s' would be part of the command",
+					strings.TrimSuffix(ce.Command, " "+placeholder)))
+			}
+		}
+		if err == nil && check != nil {
+			err = check(h)
+		}
+		res = append(res, Compatibility{Dialect: d, Err: err})
+	}
+	return res
+}
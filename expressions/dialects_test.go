@@ -0,0 +1,44 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupDialect(t *testing.T) {
+	d, err := LookupDialect("quartz")
+	require.Nil(t, err)
+	assert.Equal(t, "quartz", d.Name)
+	_, err = LookupDialect("cobol")
+	assert.NotNil(t, err)
+}
+
+func TestRegisterDialect(t *testing.T) {
+	defer func(saved []Dialect) { dialects = saved }(Dialects())
+	RegisterDialect(Dialect{Name: "vixie", New: NewRobfigSyntax})
+	RegisterDialect(Dialect{Name: "other", New: NewRobfigSyntax})
+	assert.Equal(t, 9, len(Dialects()))
+	d, err := LookupDialect("vixie")
+	require.Nil(t, err)
+	assert.False(t, d.Command)
+}
+
+func TestCompat(t *testing.T) {
+	accepted := func(input string) []string {
+		res := []string{}
+		for _, c := range Compat(input, nil) {
+			if c.Err == nil {
+				res = append(res, c.Dialect.Name)
+			}
+		}
+		return res
+	}
+	assert.Equal(t, []string{"vixie", "kubernetes", "robfig"}, accepted("0 9 * * 1-5"))
+	assert.Equal(t, []string{"quartz", "robfig", "spring"}, accepted("0 0 9 ? * MON-FRI"))
+	assert.Equal(t, []string{"aws"}, accepted("cron(0 9 ? * 2-6 *)"))
+	assert.Equal(t, []string{"systemd"}, accepted("Mon..Fri 09:00"))
+	assert.Equal(t, []string{"robfig", "azure", "spring"}, accepted("0 0 9 * * *"))
+	assert.Equal(t, []string{"vixie", "kubernetes", "robfig", "spring"}, accepted("@daily"))
+}
@@ -0,0 +1,130 @@
+package expressions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/reclaro/cep/utils"
+)
+
+// QuartzSyntax is an expression holder for the cron expressions of the Quartz scheduler: 6 or 7 fields
+// separated by white spaces in the order second, minute, hour, day of month, month, day of week and the
+// optional year, without a command. One of the day fields must be ?.
+type QuartzSyntax struct {
+	input                string
+	cronElements         *CronElements
+	regExpTokenValidator *regexp.Regexp
+}
+
+// quartzDayNames maps the names of the days of the week as Quartz and AWS do, the week goes from Sunday (1)
+// to Saturday (7)
+var quartzDayNames = map[string]string{"SUN": "1", "MON": "2", "TUE": "3", "WED": "4", "THU": "5", "FRI": "6", "SAT": "7"}
+
+var (
+	quartzDayMonthRule = regexp.MustCompile(`^L$|^L-[0-9]+$|^LW$|^[0-9]+W$`)
+	quartzDayWeekRule  = regexp.MustCompile(`^([1-7])(L|#[0-9])$`)
+)
+
+/*
+NewQuartzSyntax implements the Holder interface for the Quartz cron expressions.
+Each field can be one of the following:
+int | int-int | * | * /int | int/int | int-int/int
+and the fields can be lists of them separated by a comma. The months accept also JAN-DEC and the days of
+the week SUN-SAT in any case, the days of the week are numbered from 1 (Sunday) to 7 (Saturday).
+The day of the month can also be L (the last day), L-n (n days before the last day), LW (the last weekday)
+or nW (the weekday nearest to the day n), the day of the week can be L (Saturday), dL (the last day d of
+the month) or d#n (the nth day d of the month).
+*/
+func NewQuartzSyntax(input string) (Holder, error) {
+	qs := &QuartzSyntax{
+		input:                input,
+		regExpTokenValidator: regexp.MustCompile(`^[0-9]+$|^\*$|^[0-9]+\-[0-9]+$|^\*\/[0-9]+$|^[0-9]+\/[0-9]+$|^[0-9]+\-[0-9]+\/[0-9]+$`),
+	}
+	return qs, nil
+}
+
+// ValidateExpression receives an input string and return an error if it is not a valid Quartz expression
+func (qs *QuartzSyntax) ValidateExpression(input string) error {
+	qs.input = input
+	qs.cronElements = nil
+	_, err := qs.Elements()
+	return err
+}
+
+// Elements return the fields of the expression or error if the expression is invalid
+func (qs *QuartzSyntax) Elements() (*CronElements, error) {
+	if qs.cronElements != nil {
+		return qs.cronElements, nil
+	}
+	tokens := strings.Fields(qs.input)
+	if len(tokens) != 6 && len(tokens) != 7 {
+		return nil, errors.New(fmt.Sprintf("Number of fields incorrect for Quartz, found %d and expected 6 or 7", len(tokens)))
+	}
+	year := ""
+	if len(tokens) == 7 {
+		year = tokens[6]
+		if err := validateList(qs.regExpTokenValidator, year, qs.input); err != nil {
+			return nil, err
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if err := validateList(qs.regExpTokenValidator, tokens[i], qs.input); err != nil {
+			return nil, err
+		}
+	}
+	month := utils.StringToNumber(tokens[4], monthNames)
+	if err := validateList(qs.regExpTokenValidator, month, qs.input); err != nil {
+		return nil, err
+	}
+	dom, dow, err := quartzDays(qs.regExpTokenValidator, tokens[3], tokens[5], qs.input)
+	if err != nil {
+		return nil, err
+	}
+	qs.cronElements = &CronElements{
+		Second:        tokens[0],
+		Minute:        tokens[1],
+		Hour:          tokens[2],
+		DayMonth:      dom,
+		Month:         month,
+		DayWeek:       dow,
+		Year:          year,
+		MatchBothDays: true,
+	}
+	return qs.cronElements, nil
+}
+
+// quartzDays converts the day fields of Quartz and AWS, where one of the two fields must be ? and the days
+// of the week go from Sunday (1) to Saturday (7), to the fields used by the parsers
+func quartzDays(re *regexp.Regexp, dom string, dow string, input string) (string, string, error) {
+	dom = strings.ToUpper(dom)
+	dow = utils.StringToNumber(dow, quartzDayNames)
+	if (dom == "?") == (dow == "?") {
+		return "", "", errors.New(fmt.Sprintf("Invalid input string '%s', one of the day of the month and the day of the week must be ?", input))
+	}
+
+	switch {
+	case dom == "?":
+		dom = "*"
+	case !quartzDayMonthRule.MatchString(dom):
+		if err := validateList(re, dom, input); err != nil {
+			return "", "", err
+		}
+	}
+
+	var err error
+	switch m := quartzDayWeekRule.FindStringSubmatch(dow); {
+	case dow == "?":
+		dow = "*"
+	case dow == "L":
+		dow = "6"
+	case m != nil:
+		d, _ := strconv.Atoi(m[1])
+		dow = fmt.Sprintf("%d%s", d-1, m[2])
+	default:
+		dow, err = expandDaysOfWeek(dow, 1, func(d int) int { return d - 1 }, input)
+	}
+	return dom, dow, err
+}
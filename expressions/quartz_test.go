@@ -0,0 +1,56 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuartzElements(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected *CronElements
+	}{
+		{"weekdays", "0 15 10 ? * MON-FRI",
+			&CronElements{Second: "0", Minute: "15", Hour: "10", DayMonth: "*", Month: "*", DayWeek: "1,2,3,4,5", MatchBothDays: true}},
+		{"sunday is 1", "0 15 10 ? * 1,7",
+			&CronElements{Second: "0", Minute: "15", Hour: "10", DayMonth: "*", Month: "*", DayWeek: "0,6", MatchBothDays: true}},
+		{"last friday", "0 15 10 ? * 6L",
+			&CronElements{Second: "0", Minute: "15", Hour: "10", DayMonth: "*", Month: "*", DayWeek: "5L", MatchBothDays: true}},
+		{"third friday", "0 15 10 ? * FRI#3",
+			&CronElements{Second: "0", Minute: "15", Hour: "10", DayMonth: "*", Month: "*", DayWeek: "5#3", MatchBothDays: true}},
+		{"last day of the week", "0 15 10 ? * L",
+			&CronElements{Second: "0", Minute: "15", Hour: "10", DayMonth: "*", Month: "*", DayWeek: "6", MatchBothDays: true}},
+		{"last day with year", "0 15 10 L * ? 2030-2032",
+			&CronElements{Second: "0", Minute: "15", Hour: "10", DayMonth: "L", Month: "*", DayWeek: "*", Year: "2030-2032", MatchBothDays: true}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewQuartzSyntax(tc.input)
+			require.Nil(t, err)
+			actual, err := h.Elements()
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestQuartzInvalid(t *testing.T) {
+	tcs := []string{
+		"0 15 10 * * *",
+		"0 15 10 ? * ?",
+		"0 15 10 1 * MON",
+		"0 15 10 ? * 0",
+		"15 10 ? * MON",
+		"0 15 10 ? * 8L",
+	}
+	for _, input := range tcs {
+		t.Run(input, func(t *testing.T) {
+			h, err := NewQuartzSyntax(input)
+			require.Nil(t, err)
+			assert.NotNil(t, h.ValidateExpression(input))
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package expressions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/utils"
+)
+
+// RobfigSyntax is an expression holder for the specs accepted by the robfig/cron library with the
+// optional seconds: the specs have 5 fields, or 6 fields when the first one is for the seconds, and they
+// can be one of the descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight, @hourly and
+// @every <duration>. A spec can start with the time zone in the form TZ=Europe/Rome or CRON_TZ=Europe/Rome.
+// The specs do not have a command.
+type RobfigSyntax struct {
+	name                 string
+	seconds              bool
+	timeZone             bool
+	input                string
+	location             *time.Location
+	cronElements         *CronElements
+	daysMapper           map[string]string
+	monthsMapper         map[string]string
+	regExpTokenValidator *regexp.Regexp
+}
+
+// dayNames and monthNames map the names accepted in the day of the week and month fields to their numbers
+var (
+	dayNames   = map[string]string{"SUN": "0", "MON": "1", "TUE": "2", "WED": "3", "THU": "4", "FRI": "5", "SAT": "6"}
+	monthNames = map[string]string{"JAN": "1", "FEB": "2", "MAR": "3", "APR": "4", "MAY": "5", "JUN": "6",
+		"JUL": "7", "AUG": "8", "SEP": "9", "OCT": "10", "NOV": "11", "DEC": "12"}
+)
+
+// robfigDescriptors are the predefined schedules of robfig/cron, the fields are minute, hour,
+// day of month, month and day of week
+var robfigDescriptors = map[string][]string{
+	"@yearly":   {"0", "0", "1", "1", "*"},
+	"@annually": {"0", "0", "1", "1", "*"},
+	"@monthly":  {"0", "0", "1", "*", "*"},
+	"@weekly":   {"0", "0", "*", "*", "0"},
+	"@daily":    {"0", "0", "*", "*", "*"},
+	"@midnight": {"0", "0", "*", "*", "*"},
+	"@hourly":   {"0", "*", "*", "*", "*"},
+}
+
+/*
+NewRobfigSyntax implements the Holder interface for the robfig/cron specs.
+The fields are separated by white spaces, each field can be one of the following:
+int | int-int | * | ? | * /int | int/int | int-int/int
+and the fields can be lists of them separated by a comma. The months accept also JAN-DEC and the
+days of the week SUN-SAT in any case.
+*/
+func NewRobfigSyntax(input string) (Holder, error) {
+	rs := &RobfigSyntax{
+		name:                 "robfig/cron",
+		seconds:              true,
+		timeZone:             true,
+		input:                input,
+		daysMapper:           dayNames,
+		monthsMapper:         monthNames,
+		regExpTokenValidator: regexp.MustCompile(`^[0-9]+$|^\*$|^\?$|^[0-9]+\-[0-9]+$|^\*\/[0-9]+$|^[0-9]+\/[0-9]+$|^[0-9]+\-[0-9]+\/[0-9]+$`),
+	}
+	return rs, nil
+}
+
+// NewKubernetesSyntax implements the Holder interface for the schedules of the Kubernetes CronJobs.
+// Kubernetes parses the schedules with robfig/cron without the seconds and it rejects the time zones
+// in the schedule, they are set in the timeZone field of the CronJob instead
+func NewKubernetesSyntax(input string) (Holder, error) {
+	h, _ := NewRobfigSyntax(input)
+	rs := h.(*RobfigSyntax)
+	rs.name = "Kubernetes"
+	rs.seconds = false
+	rs.timeZone = false
+	return rs, nil
+}
+
+// ValidateExpression receives an input string and return an error if it is not a valid robfig/cron spec
+func (rs *RobfigSyntax) ValidateExpression(input string) error {
+	rs.input = input
+	rs.cronElements = nil
+	_, err := rs.Elements()
+	return err
+}
+
+// Elements return the fields of the spec or error if the spec is invalid
+func (rs *RobfigSyntax) Elements() (*CronElements, error) {
+	if rs.cronElements != nil {
+		return rs.cronElements, nil
+	}
+	tokens := strings.Fields(rs.input)
+	if len(tokens) > 0 && (strings.HasPrefix(tokens[0], "TZ=") || strings.HasPrefix(tokens[0], "CRON_TZ=")) {
+		if !rs.timeZone {
+			return nil, errors.New(fmt.Sprintf("Time zones are not supported in the schedules of %s", rs.name))
+		}
+		name := tokens[0][strings.Index(tokens[0], "=")+1:]
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid time zone '%s'", name))
+		}
+		rs.location = loc
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("Empty spec")
+	}
+
+	if strings.HasPrefix(tokens[0], "@") {
+		ce, err := rs.descriptor(tokens)
+		if err != nil {
+			return nil, err
+		}
+		rs.cronElements = ce
+		return ce, nil
+	}
+
+	if !rs.seconds && len(tokens) != 5 {
+		return nil, errors.New(fmt.Sprintf("Number of fields incorrect for %s, found %d and expected 5", rs.name, len(tokens)))
+	}
+	if len(tokens) != 5 && len(tokens) != 6 {
+		return nil, errors.New(fmt.Sprintf("Number of fields incorrect for %s, found %d and expected 5 or 6", rs.name, len(tokens)))
+	}
+	second := ""
+	if len(tokens) == 6 {
+		second = tokens[0]
+		tokens = tokens[1:]
+	}
+	tokens[3] = utils.StringToNumber(tokens[3], rs.monthsMapper)
+	tokens[4] = utils.StringToNumber(tokens[4], rs.daysMapper)
+	for i, t := range tokens {
+		// ? is the same as * in robfig/cron
+		if t == "?" {
+			tokens[i] = "*"
+		}
+	}
+	if second == "?" {
+		second = "*"
+	}
+	if second != "" {
+		if err := rs.validateTokens(second); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range tokens {
+		if err := rs.validateTokens(t); err != nil {
+			return nil, err
+		}
+	}
+	rs.cronElements = &CronElements{
+		Second:   second,
+		Minute:   tokens[0],
+		Hour:     tokens[1],
+		DayMonth: tokens[2],
+		Month:    tokens[3],
+		DayWeek:  tokens[4],
+	}
+	return rs.cronElements, nil
+}
+
+// Location returns the time zone of the spec or nil if the spec does not have one
+func (rs *RobfigSyntax) Location() *time.Location {
+	return rs.location
+}
+
+// descriptor returns the fields for one of the predefined schedules
+func (rs *RobfigSyntax) descriptor(tokens []string) (*CronElements, error) {
+	name := tokens[0]
+	if name == "@every" {
+		if len(tokens) != 2 {
+			return nil, errors.New(fmt.Sprintf("Invalid spec '%s', @every requires a duration", rs.input))
+		}
+		d, err := time.ParseDuration(tokens[1])
+		if err != nil || d <= 0 {
+			return nil, errors.New(fmt.Sprintf("Invalid duration '%s' for @every", tokens[1]))
+		}
+		// as in robfig/cron the intervals shorter than one second are rounded up to one second and the
+		// others are rounded down to the second
+		if d < time.Second {
+			d = time.Second
+		}
+		d = d - d%time.Second
+		return &CronElements{Every: d.String()}, nil
+	}
+	fields, ok := robfigDescriptors[name]
+	if !ok || len(tokens) != 1 {
+		return nil, errors.New(fmt.Sprintf("Unrecognized descriptor '%s'", rs.input))
+	}
+	return &CronElements{
+		Minute:   fields[0],
+		Hour:     fields[1],
+		DayMonth: fields[2],
+		Month:    fields[3],
+		DayWeek:  fields[4],
+	}, nil
+}
+
+// validateTokens checks the syntax of each part of a comma separated field
+func (rs *RobfigSyntax) validateTokens(token string) error {
+	for _, str := range strings.Split(token, ",") {
+		if !rs.regExpTokenValidator.MatchString(str) {
+			return errors.New(fmt.Sprintf("Invalid input string '%s' please check the correct syntax", rs.input))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRobfigElements(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected *CronElements
+	}{
+		{"five fields", "*/15 0 1,15 * MON-FRI",
+			&CronElements{Minute: "*/15", Hour: "0", DayMonth: "1,15", Month: "*", DayWeek: "1-5"}},
+		{"seconds", "30 0 9 ? jan-mar *",
+			&CronElements{Second: "30", Minute: "0", Hour: "9", DayMonth: "*", Month: "1-3", DayWeek: "*"}},
+		{"white spaces", "0  9\t* * *",
+			&CronElements{Minute: "0", Hour: "9", DayMonth: "*", Month: "*", DayWeek: "*"}},
+		{"descriptor", "@weekly",
+			&CronElements{Minute: "0", Hour: "0", DayMonth: "*", Month: "*", DayWeek: "0"}},
+		{"every", "@every 1h30m", &CronElements{Every: "1h30m0s"}},
+		{"every rounded down", "@every 90500ms", &CronElements{Every: "1m30s"}},
+		{"every rounded up", "@every 10ms", &CronElements{Every: "1s"}},
+		{"time zone", "CRON_TZ=Europe/Rome 0 9 * * *",
+			&CronElements{Minute: "0", Hour: "9", DayMonth: "*", Month: "*", DayWeek: "*"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewRobfigSyntax(tc.input)
+			require.Nil(t, err)
+			actual, err := h.Elements()
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestKubernetesInvalid(t *testing.T) {
+	for _, input := range []string{"0 0 9 * * *", "CRON_TZ=UTC 0 9 * * *"} {
+		h, err := NewKubernetesSyntax(input)
+		require.Nil(t, err)
+		assert.NotNil(t, h.ValidateExpression(input))
+	}
+}
+
+func TestRobfigLocation(t *testing.T) {
+	h, err := NewRobfigSyntax("TZ=Asia/Tokyo @daily")
+	require.Nil(t, err)
+	require.Nil(t, h.ValidateExpression("TZ=Asia/Tokyo @daily"))
+	assert.Equal(t, "Asia/Tokyo", h.(*RobfigSyntax).Location().String())
+}
+
+func TestRobfigInvalid(t *testing.T) {
+	tcs := []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"0 9 * * * /bin/ls",
+		"0 9 L * *",
+		"@every",
+		"@every 1x",
+		"@every -1h",
+		"@reboot",
+		"@DAILY",
+		"TZ=Nowhere/Nothing @daily",
+	}
+	for _, input := range tcs {
+		t.Run(input, func(t *testing.T) {
+			h, err := NewRobfigSyntax(input)
+			require.Nil(t, err)
+			assert.NotNil(t, h.ValidateExpression(input))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package expressions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/reclaro/cep/utils"
+)
+
+// SpringSyntax is an expression holder for the cron expressions of Spring @Scheduled (Spring 5.3+):
+// 6 fields separated by white spaces in the order second, minute, hour, day of month, month and day of
+// week, without a command. A day must match both the day of the month and the day of the week.
+type SpringSyntax struct {
+	input                string
+	cronElements         *CronElements
+	regExpTokenValidator *regexp.Regexp
+}
+
+// springMacros are the predefined expressions of Spring
+var springMacros = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// springDayNames maps the names of the days of the week as Spring does, Sunday is the last day of the
+// week and both 0 and 7 are Sunday
+var springDayNames = map[string]string{"MON": "1", "TUE": "2", "WED": "3", "THU": "4", "FRI": "5", "SAT": "6", "SUN": "7"}
+
+var (
+	springDayMonthRule = regexp.MustCompile(`^L$|^L-[0-9]+$|^LW$|^[0-9]+W$`)
+	springDayWeekRule  = regexp.MustCompile(`^([0-7])(L|#[0-9])$`)
+)
+
+/*
+NewSpringSyntax implements the Holder interface for the Spring cron expressions.
+Each field can be one of the following:
+int | int-int | * | * /int | int/int | int-int/int
+and the fields can be lists of them separated by a comma. The months accept also JAN-DEC and the days of
+the week MON-SUN in any case, ? is the same as * in the day fields.
+The day of the month can also be L (the last day), L-n (n days before the last day), LW (the last weekday)
+or nW (the weekday nearest to the day n), the day of the week can be dL (the last day d of the month)
+or d#n (the nth day d of the month).
+Spring accepts also the macros @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly.
+*/
+func NewSpringSyntax(input string) (Holder, error) {
+	ss := &SpringSyntax{
+		input:                input,
+		regExpTokenValidator: regexp.MustCompile(`^[0-9]+$|^\*$|^[0-9]+\-[0-9]+$|^\*\/[0-9]+$|^[0-9]+\/[0-9]+$|^[0-9]+\-[0-9]+\/[0-9]+$`),
+	}
+	return ss, nil
+}
+
+// ValidateExpression receives an input string and return an error if it is not a valid Spring expression
+func (ss *SpringSyntax) ValidateExpression(input string) error {
+	ss.input = input
+	ss.cronElements = nil
+	_, err := ss.Elements()
+	return err
+}
+
+// Elements return the fields of the expression or error if the expression is invalid
+func (ss *SpringSyntax) Elements() (*CronElements, error) {
+	if ss.cronElements != nil {
+		return ss.cronElements, nil
+	}
+	input := strings.TrimSpace(ss.input)
+	if macro, ok := springMacros[input]; ok {
+		input = macro
+	}
+	tokens := strings.Fields(input)
+	if len(tokens) != 6 {
+		return nil, errors.New(fmt.Sprintf("Number of fields incorrect for Spring, found %d and expected 6", len(tokens)))
+	}
+	for i := 0; i < 3; i++ {
+		if err := validateList(ss.regExpTokenValidator, tokens[i], ss.input); err != nil {
+			return nil, err
+		}
+	}
+
+	dom := strings.ToUpper(tokens[3])
+	if dom == "?" {
+		dom = "*"
+	}
+	if !springDayMonthRule.MatchString(dom) {
+		if err := validateList(ss.regExpTokenValidator, dom, ss.input); err != nil {
+			return nil, err
+		}
+	}
+
+	month := utils.StringToNumber(tokens[4], monthNames)
+	if err := validateList(ss.regExpTokenValidator, month, ss.input); err != nil {
+		return nil, err
+	}
+
+	dow, err := ss.daysOfWeek(tokens[5])
+	if err != nil {
+		return nil, err
+	}
+
+	ss.cronElements = &CronElements{
+		Second:        tokens[0],
+		Minute:        tokens[1],
+		Hour:          tokens[2],
+		DayMonth:      dom,
+		Month:         month,
+		DayWeek:       dow,
+		MatchBothDays: true,
+	}
+	return ss.cronElements, nil
+}
+
+// daysOfWeek converts the day of the week field to the days from 0 to 6 used by the parsers. In Spring
+// the days go from Monday (1) to Sunday (7 or 0), so the ranges and the steps are expanded to a list
+// of days, e.g. */2 is Monday, Wednesday, Friday and Sunday
+func (ss *SpringSyntax) daysOfWeek(field string) (string, error) {
+	field = utils.StringToNumber(field, springDayNames)
+	if field == "?" || field == "*" {
+		return "*", nil
+	}
+	if m := springDayWeekRule.FindStringSubmatch(field); m != nil {
+		d, _ := strconv.Atoi(m[1])
+		return fmt.Sprintf("%d%s", d%7, m[2]), nil
+	}
+	return expandDaysOfWeek(field, 0, func(d int) int { return d % 7 }, ss.input)
+}
@@ -0,0 +1,66 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpringElements(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected *CronElements
+	}{
+		{"weekdays", "0 30 10 ? * MON-FRI",
+			&CronElements{Second: "0", Minute: "30", Hour: "10", DayMonth: "*", Month: "*", DayWeek: "1,2,3,4,5", MatchBothDays: true}},
+		{"sunday is 7", "0 0 9 * JAN 5-7",
+			&CronElements{Second: "0", Minute: "0", Hour: "9", DayMonth: "*", Month: "1", DayWeek: "0,5,6", MatchBothDays: true}},
+		{"steps start on monday", "0 0 9 * * */2",
+			&CronElements{Second: "0", Minute: "0", Hour: "9", DayMonth: "*", Month: "*", DayWeek: "0,1,3,5", MatchBothDays: true}},
+		{"all days", "0 0 9 * * 0-6,SUN",
+			&CronElements{Second: "0", Minute: "0", Hour: "9", DayMonth: "*", Month: "*", DayWeek: "*", MatchBothDays: true}},
+		{"last day", "0 0 9 L * ?",
+			&CronElements{Second: "0", Minute: "0", Hour: "9", DayMonth: "L", Month: "*", DayWeek: "*", MatchBothDays: true}},
+		{"nearest weekday", "0 0 9 15w * ?",
+			&CronElements{Second: "0", Minute: "0", Hour: "9", DayMonth: "15W", Month: "*", DayWeek: "*", MatchBothDays: true}},
+		{"last friday", "0 0 9 ? * FRIL",
+			&CronElements{Second: "0", Minute: "0", Hour: "9", DayMonth: "*", Month: "*", DayWeek: "5L", MatchBothDays: true}},
+		{"second sunday", "0 0 9 ? * 7#2",
+			&CronElements{Second: "0", Minute: "0", Hour: "9", DayMonth: "*", Month: "*", DayWeek: "0#2", MatchBothDays: true}},
+		{"macro", "@hourly",
+			&CronElements{Second: "0", Minute: "0", Hour: "*", DayMonth: "*", Month: "*", DayWeek: "*", MatchBothDays: true}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewSpringSyntax(tc.input)
+			require.Nil(t, err)
+			actual, err := h.Elements()
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestSpringInvalid(t *testing.T) {
+	tcs := []string{
+		"0 9 * * *",
+		"0 0 9 * * * /bin/ls",
+		"? 0 9 * * *",
+		"0 0 9 * ? *",
+		"0 0 9 L,1 * *",
+		"0 0 9 * * L",
+		"0 0 9 * * 8",
+		"0 0 9 * * SUN-SAT",
+		"0 0 9 * * */0",
+		"@every 1h",
+	}
+	for _, input := range tcs {
+		t.Run(input, func(t *testing.T) {
+			h, err := NewSpringSyntax(input)
+			require.Nil(t, err)
+			assert.NotNil(t, h.ValidateExpression(input))
+		})
+	}
+}
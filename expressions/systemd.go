@@ -0,0 +1,230 @@
+package expressions
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SystemdSyntax is an expression holder for the calendar events of the systemd timers (OnCalendar=) in
+// the form "DayOfWeek Year-Month-Day Hour:Minute:Second TimeZone", where every part is optional.
+// A day must match both the date and the day of the week.
+type SystemdSyntax struct {
+	input                string
+	location             *time.Location
+	cronElements         *CronElements
+	regExpTokenValidator *regexp.Regexp
+}
+
+// systemdShorthands are the calendar events that systemd accepts as a single word
+var systemdShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+}
+
+// systemdDays are the days of the week in the order used by the parsers, systemd accepts the first
+// three letters or the whole name in any case
+var systemdDays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+/*
+NewSystemdSyntax implements the Holder interface for the systemd calendar events.
+The days of the week are names, e.g. Mon or Monday, separated by a comma or as a range Mon..Fri. The date
+is Year-Month-Day or Month-Day, the time is Hour:Minute:Second or Hour:Minute, each component can be one
+of the following:
+int | int..int | * | int/int | int..int/int
+and the components can be lists of them separated by a comma. Month~Day is the Day-th last day of the
+month. The date is every day when it is missing and the time is midnight.
+systemd accepts also the shorthands minutely, hourly, daily, weekly, monthly, quarterly, semiannually,
+yearly and annually.
+*/
+func NewSystemdSyntax(input string) (Holder, error) {
+	ss := &SystemdSyntax{
+		input:                input,
+		regExpTokenValidator: regexp.MustCompile(`^[0-9]+$|^\*$|^[0-9]+\-[0-9]+$|^\*\/[0-9]+$|^[0-9]+\/[0-9]+$|^[0-9]+\-[0-9]+\/[0-9]+$`),
+	}
+	return ss, nil
+}
+
+// ValidateExpression receives an input string and return an error if it is not a valid calendar event
+func (ss *SystemdSyntax) ValidateExpression(input string) error {
+	ss.input = input
+	ss.cronElements = nil
+	_, err := ss.Elements()
+	return err
+}
+
+// Location returns the time zone of the calendar event or nil if it does not have one
+func (ss *SystemdSyntax) Location() *time.Location {
+	return ss.location
+}
+
+// Elements return the fields of the calendar event or error if the calendar event is invalid
+func (ss *SystemdSyntax) Elements() (*CronElements, error) {
+	if ss.cronElements != nil {
+		return ss.cronElements, nil
+	}
+	tokens := strings.Fields(ss.input)
+	// the time zone is the last part and it is a name, e.g. UTC or Europe/Berlin
+	if len(tokens) > 1 {
+		last := tokens[len(tokens)-1]
+		if unicode.IsLetter(rune(last[0])) {
+			loc, err := time.LoadLocation(last)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("Invalid time zone '%s'", last))
+			}
+			ss.location = loc
+			tokens = tokens[:len(tokens)-1]
+		}
+	}
+	if len(tokens) == 1 {
+		if event, ok := systemdShorthands[strings.ToLower(tokens[0])]; ok {
+			tokens = strings.Fields(event)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("Empty calendar event")
+	}
+
+	ce := &CronElements{DayWeek: "*", MatchBothDays: true}
+	var err error
+	if unicode.IsLetter(rune(tokens[0][0])) {
+		ce.DayWeek, err = ss.daysOfWeek(tokens[0])
+		if err != nil {
+			return nil, err
+		}
+		tokens = tokens[1:]
+	}
+	date := "*-*-*"
+	if len(tokens) > 0 && !strings.Contains(tokens[0], ":") {
+		date = tokens[0]
+		tokens = tokens[1:]
+	}
+	clock := "00:00:00"
+	if len(tokens) > 0 && strings.Contains(tokens[0], ":") {
+		clock = tokens[0]
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 {
+		return nil, errors.New(fmt.Sprintf("Invalid calendar event '%s', unexpected '%s'", ss.input, tokens[0]))
+	}
+	if err := ss.date(date, ce); err != nil {
+		return nil, err
+	}
+	if err := ss.clock(clock, ce); err != nil {
+		return nil, err
+	}
+	ss.cronElements = ce
+	return ce, nil
+}
+
+// daysOfWeek converts the days of the week to the day of the week field, a range can go past Saturday
+func (ss *SystemdSyntax) daysOfWeek(field string) (string, error) {
+	res := []string{}
+	for _, token := range strings.Split(field, ",") {
+		bounds := strings.Split(token, "..")
+		if len(bounds) > 2 {
+			return "", errors.New(fmt.Sprintf("Invalid day of the week '%s'", token))
+		}
+		days := []int{}
+		for _, b := range bounds {
+			d := ss.dayOfWeek(b)
+			if d < 0 {
+				return "", errors.New(fmt.Sprintf("Invalid day of the week '%s'", b))
+			}
+			days = append(days, d)
+		}
+		switch {
+		case len(days) == 1:
+			res = append(res, strconv.Itoa(days[0]))
+		case days[0] <= days[1]:
+			res = append(res, dayRange(days[0], days[1]))
+		default:
+			res = append(res, dayRange(days[0], 6), dayRange(0, days[1]))
+		}
+	}
+	return strings.Join(res, ","), nil
+}
+
+// dayRange returns the range of the days of the week from start to end
+func dayRange(start int, end int) string {
+	if start == end {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d-%d", start, end)
+}
+
+// dayOfWeek returns the number of a day of the week or -1 if the name is invalid
+func (ss *SystemdSyntax) dayOfWeek(name string) int {
+	name = strings.ToLower(name)
+	for i, d := range systemdDays {
+		if name == d || name == d[:3] {
+			return i
+		}
+	}
+	return -1
+}
+
+// date sets the year, month and day of the month fields from a date
+func (ss *SystemdSyntax) date(date string, ce *CronElements) error {
+	// Month~Day counts the days from the end of the month
+	last := ""
+	if i := strings.Index(date, "~"); i >= 0 {
+		n, err := strconv.Atoi(date[i+1:])
+		if err != nil || n < 1 || n > 31 {
+			return errors.New(fmt.Sprintf("Invalid last day of the month '%s'", date[i+1:]))
+		}
+		last = "L"
+		if n > 1 {
+			last = fmt.Sprintf("L-%d", n-1)
+		}
+		date = date[:i] + "-*"
+	}
+	parts := strings.Split(date, "-")
+	if len(parts) == 2 {
+		parts = append([]string{"*"}, parts...)
+	}
+	if len(parts) != 3 {
+		return errors.New(fmt.Sprintf("Invalid date '%s' please check the correct syntax", date))
+	}
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(p, "..", "-")
+		if err := validateList(ss.regExpTokenValidator, parts[i], ss.input); err != nil {
+			return err
+		}
+	}
+	if last != "" {
+		parts[2] = last
+	}
+	ce.Year, ce.Month, ce.DayMonth = parts[0], parts[1], parts[2]
+	return nil
+}
+
+// clock sets the hour, minute and second fields from a time, the seconds are 0 when they are missing
+func (ss *SystemdSyntax) clock(clock string, ce *CronElements) error {
+	parts := strings.Split(clock, ":")
+	if len(parts) == 2 {
+		parts = append(parts, "00")
+	}
+	if len(parts) != 3 {
+		return errors.New(fmt.Sprintf("Invalid time '%s' please check the correct syntax", clock))
+	}
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(p, "..", "-")
+		if err := validateList(ss.regExpTokenValidator, parts[i], ss.input); err != nil {
+			return err
+		}
+	}
+	ce.Hour, ce.Minute, ce.Second = parts[0], parts[1], parts[2]
+	return nil
+}
@@ -0,0 +1,66 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdElements(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected *CronElements
+	}{
+		{"shorthand", "daily",
+			&CronElements{Second: "00", Minute: "00", Hour: "00", DayMonth: "*", Month: "*", DayWeek: "*", Year: "*", MatchBothDays: true}},
+		{"weekdays", "Mon..Fri 09:30",
+			&CronElements{Second: "00", Minute: "30", Hour: "09", DayMonth: "*", Month: "*", DayWeek: "1-5", Year: "*", MatchBothDays: true}},
+		{"weekend", "Sat..sunday *-*-* *:0/15:00",
+			&CronElements{Second: "00", Minute: "0/15", Hour: "*", DayMonth: "*", Month: "*", DayWeek: "6,0", Year: "*", MatchBothDays: true}},
+		{"date", "2030-01..03-01,15",
+			&CronElements{Second: "00", Minute: "00", Hour: "00", DayMonth: "01,15", Month: "01-03", DayWeek: "*", Year: "2030", MatchBothDays: true}},
+		{"last days", "*-02~03 12:00:30",
+			&CronElements{Second: "30", Minute: "00", Hour: "12", DayMonth: "L-2", Month: "02", DayWeek: "*", Year: "*", MatchBothDays: true}},
+		{"time zone", "weekly Europe/Berlin",
+			&CronElements{Second: "00", Minute: "00", Hour: "00", DayMonth: "*", Month: "*", DayWeek: "1", Year: "*", MatchBothDays: true}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewSystemdSyntax(tc.input)
+			require.Nil(t, err)
+			actual, err := h.Elements()
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestSystemdLocation(t *testing.T) {
+	h, err := NewSystemdSyntax("*-*-* 06:00 UTC")
+	require.Nil(t, err)
+	_, err = h.Elements()
+	require.Nil(t, err)
+	assert.Equal(t, "UTC", h.(*SystemdSyntax).Location().String())
+}
+
+func TestSystemdInvalid(t *testing.T) {
+	tcs := []string{
+		"",
+		"Mon..Fri..Sat 09:00",
+		"Funday 09:00",
+		"*-*-* 09",
+		"*-*-*-* 09:00",
+		"*-*-* 09:00 Nowhere/Nothing",
+		"*-*~0",
+		"0 9 * * *",
+	}
+	for _, input := range tcs {
+		t.Run(input, func(t *testing.T) {
+			h, err := NewSystemdSyntax(input)
+			require.Nil(t, err)
+			assert.NotNil(t, h.ValidateExpression(input))
+		})
+	}
+}
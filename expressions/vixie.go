@@ -0,0 +1,58 @@
+package expressions
+
+import (
+	"strings"
+)
+
+// vixieMacros maps the nicknames supported by Vixie cron to the equivalent time fields
+var vixieMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// VixieMacro returns the time fields of a nickname of Vixie cron, e.g. @daily, and false if the
+// nickname is not supported
+func VixieMacro(name string) (string, bool) {
+	schedule, ok := vixieMacros[strings.ToLower(name)]
+	return schedule, ok
+}
+
+// vixieSyntax is the DefaultSyntax of a crontab line that can start with a nickname instead of the
+// time fields, e.g. @daily /usr/bin/backup
+type vixieSyntax struct {
+	Holder
+}
+
+// NewVixieSyntax implements the Holder interface for the lines of the crontab files of Vixie cron, the
+// nicknames are replaced by their time fields and the line is validated by the DefaultSyntax
+func NewVixieSyntax(input string) (Holder, error) {
+	h, err := NewDefaultSyntax(expandVixieMacro(input))
+	if err != nil {
+		return nil, err
+	}
+	return &vixieSyntax{Holder: h}, nil
+}
+
+// ValidateExpression validates the input with the nickname replaced by its time fields
+func (vs *vixieSyntax) ValidateExpression(input string) error {
+	return vs.Holder.ValidateExpression(expandVixieMacro(input))
+}
+
+// expandVixieMacro replaces the nickname at the start of the input with its time fields, the input is
+// returned as it is if it does not start with a supported nickname
+func expandVixieMacro(input string) string {
+	fields := strings.SplitN(strings.TrimSpace(input), " ", 2)
+	schedule, ok := VixieMacro(fields[0])
+	if !ok {
+		return input
+	}
+	if len(fields) == 1 {
+		return schedule
+	}
+	return schedule + " " + fields[1]
+}
@@ -0,0 +1,47 @@
+package expressions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVixieSyntax(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    string
+		expected CronElements
+	}{
+		{"fields", "*/5 9 * * 1-5 /usr/bin/find", CronElements{Minute: "*/5", Hour: "9", DayMonth: "*", Month: "*", DayWeek: "1-5", Command: "/usr/bin/find"}},
+		{"macro", "@daily /usr/bin/find", CronElements{Minute: "0", Hour: "0", DayMonth: "*", Month: "*", DayWeek: "*", Command: "/usr/bin/find"}},
+		{"macro in upper case", "@WEEKLY /usr/bin/find -x", CronElements{Minute: "0", Hour: "0", DayMonth: "*", Month: "*", DayWeek: "0", Command: "/usr/bin/find -x"}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := NewVixieSyntax(tc.input)
+			require.Nil(t, err)
+			require.Nil(t, h.ValidateExpression(tc.input))
+			ce, err := h.Elements()
+			require.Nil(t, err)
+			assert.Equal(t, tc.expected.Minute, ce.Minute)
+			assert.Equal(t, tc.expected.Hour, ce.Hour)
+			assert.Equal(t, tc.expected.DayMonth, ce.DayMonth)
+			assert.Equal(t, tc.expected.Month, ce.Month)
+			assert.Equal(t, tc.expected.DayWeek, ce.DayWeek)
+			assert.Equal(t, tc.expected.Command, ce.Command)
+		})
+	}
+}
+
+func TestVixieSyntaxInvalid(t *testing.T) {
+	for _, input := range []string{"@daily", "@fortnightly /usr/bin/find", "* * * /usr/bin/find"} {
+		t.Run(input, func(t *testing.T) {
+			h, err := NewVixieSyntax(input)
+			if err == nil {
+				err = h.ValidateExpression(input)
+			}
+			assert.NotNil(t, err)
+		})
+	}
+}
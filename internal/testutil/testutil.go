@@ -0,0 +1,22 @@
+/*
+Package testutil contains the fixtures shared by the tests of the packages that use the parsed cron
+expressions.
+*/
+package testutil
+
+import (
+	"testing"
+
+	"github.com/reclaro/cep/crontab"
+	"github.com/reclaro/cep/parsers"
+	"github.com/stretchr/testify/require"
+)
+
+// Results expands a cron expression in the crontab syntax, with or without the command, and it fails the
+// test if the expression is invalid
+func Results(t testing.TB, expression string) *parsers.CronResults {
+	t.Helper()
+	res, err := crontab.ExpandSchedule(expression)
+	require.Nil(t, err)
+	return res
+}
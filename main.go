@@ -6,7 +6,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/reclaro/cep/expressions"
 	"github.com/reclaro/cep/parsers"
 	"github.com/reclaro/cep/printers"
 )
@@ -15,8 +14,17 @@ import (
 This script parses a cron string and expands each field to show the times at which it will run
 */
 func main() {
+	dialect := flag.String("dialect", "vixie", "syntax of the expression, see cep compat for the dialects")
 	flag.Parse()
 
+	// the first argument can be the name of a sub command, e.g. cep stagger crontab.txt
+	if len(flag.Args()) > 0 {
+		if run, ok := commands[flag.Arg(0)]; ok {
+			exitOnError(run(flag.Args()[1:]))
+			return
+		}
+	}
+
 	if len(flag.Args()) > 1 {
 		fmt.Println("The program accept only a single parameter as input string")
 		os.Exit(1)
@@ -29,7 +37,7 @@ func main() {
 	}
 
 	// we instantiate the expression holder that is responsible for checking the correctness of the cron expression string
-	expressionHolder, err := expressions.NewDefaultSyntax(cmd)
+	expressionHolder, err := newHolder(*dialect, cmd)
 	if err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
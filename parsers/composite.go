@@ -0,0 +1,139 @@
+package parsers
+
+import (
+	"strings"
+	"time"
+)
+
+// Operation is the set operation used by a Composite schedule to combine its schedules
+type Operation int
+
+const (
+	// Union runs when at least one of the schedules runs
+	Union Operation = iota
+	// Intersection runs when all the schedules run
+	Intersection
+	// Difference runs when the first schedule runs and none of the others runs
+	Difference
+)
+
+// String returns the name of the operation
+func (o Operation) String() string {
+	switch o {
+	case Union:
+		return "or"
+	case Intersection:
+		return "and"
+	case Difference:
+		return "except"
+	}
+	return "unknown"
+}
+
+/*
+Composite is a Schedule that combines other schedules with a set operation, e.g. "weekdays at 9 except
+public holidays" is the Difference between "0 9 * * 1-5" and a schedule of the holidays.
+Composites can be nested to build any expression of unions, intersections and differences.
+*/
+type Composite struct {
+	Operation Operation
+	Schedules []Schedule
+}
+
+// NewUnion returns a schedule that runs when at least one of the input schedules runs
+func NewUnion(schedules ...Schedule) *Composite {
+	return &Composite{Operation: Union, Schedules: schedules}
+}
+
+// NewIntersection returns a schedule that runs when all the input schedules run
+func NewIntersection(schedules ...Schedule) *Composite {
+	return &Composite{Operation: Intersection, Schedules: schedules}
+}
+
+// NewDifference returns a schedule that runs when s runs and none of the excluded schedules runs
+func NewDifference(s Schedule, excluded ...Schedule) *Composite {
+	return &Composite{Operation: Difference, Schedules: append([]Schedule{s}, excluded...)}
+}
+
+// Next returns the first time after t when the composite schedule runs or the zero time if it
+// does not run in the next MaxYears years
+func (c *Composite) Next(t time.Time) time.Time {
+	if len(c.Schedules) == 0 {
+		return time.Time{}
+	}
+	limit := t.AddDate(MaxYears, 0, 0)
+	switch c.Operation {
+	case Union:
+		next := time.Time{}
+		for _, s := range c.Schedules {
+			n := s.Next(t)
+			if !n.IsZero() && (next.IsZero() || n.Before(next)) {
+				next = n
+			}
+		}
+		return next
+	case Intersection:
+		// every schedule is asked for its next run from the latest candidate, when they all agree
+		// the candidate is a run of the intersection
+		next := c.Schedules[0].Next(t)
+		for !next.IsZero() && next.Before(limit) {
+			latest := next
+			for _, s := range c.Schedules[1:] {
+				n := from(s, next)
+				if n.IsZero() {
+					return time.Time{}
+				}
+				if n.After(latest) {
+					latest = n
+				}
+			}
+			if latest.Equal(next) {
+				return next
+			}
+			next = from(c.Schedules[0], latest)
+		}
+	case Difference:
+		excluded := NewUnion(c.Schedules[1:]...)
+		for next := c.Schedules[0].Next(t); !next.IsZero() && next.Before(limit); next = c.Schedules[0].Next(next) {
+			if !excluded.Matches(next) {
+				return next
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// Matches returns true if the composite schedule runs in the minute of t
+func (c *Composite) Matches(t time.Time) bool {
+	if len(c.Schedules) == 0 {
+		return false
+	}
+	switch c.Operation {
+	case Union:
+		for _, s := range c.Schedules {
+			if s.Matches(t) {
+				return true
+			}
+		}
+		return false
+	case Intersection:
+		for _, s := range c.Schedules {
+			if !s.Matches(t) {
+				return false
+			}
+		}
+		return true
+	case Difference:
+		return c.Schedules[0].Matches(t) && !NewUnion(c.Schedules[1:]...).Matches(t)
+	}
+	return false
+}
+
+// String returns a description of the composite schedule, e.g. (A) except (B)
+func (c *Composite) String() string {
+	parts := []string{}
+	for _, s := range c.Schedules {
+		parts = append(parts, "("+Describe(s)+")")
+	}
+	return strings.Join(parts, " "+c.Operation.String()+" ")
+}
@@ -0,0 +1,77 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompositeUnion(t *testing.T) {
+	s := NewUnion(resultsFromString(t, "0 9 * * 1-5 cmd"), resultsFromString(t, "30 10 * * 0,6 cmd"))
+	// Friday 5th of March 2021
+	from := time.Date(2021, time.March, 5, 10, 0, 0, 0, time.UTC)
+	expected := []time.Time{
+		time.Date(2021, time.March, 6, 10, 30, 0, 0, time.UTC),
+		time.Date(2021, time.March, 7, 10, 30, 0, 0, time.UTC),
+		time.Date(2021, time.March, 8, 9, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, expected, Upcoming(s, from, 3))
+	assert.True(t, s.Matches(expected[0]))
+	assert.False(t, s.Matches(from))
+	assert.Equal(t, "(0 9 * * 1-5) or (30 10 * * 0,6)", s.String())
+}
+
+func TestCompositeIntersection(t *testing.T) {
+	// the 13th of the month at 9 when it is a Friday
+	s := NewIntersection(resultsFromString(t, "0 9 13 * * cmd"), resultsFromString(t, "0 * * * 5 cmd"))
+	from := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	expected := []time.Time{
+		time.Date(2021, time.August, 13, 9, 0, 0, 0, time.UTC),
+		time.Date(2022, time.May, 13, 9, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, expected, Upcoming(s, from, 2))
+	assert.True(t, s.Matches(expected[0]))
+	assert.False(t, s.Matches(time.Date(2021, time.September, 13, 9, 0, 0, 0, time.UTC)))
+
+	never := NewIntersection(resultsFromString(t, "0 * * * * cmd"), resultsFromString(t, "1 * * * * cmd"))
+	assert.True(t, never.Next(from).IsZero())
+}
+
+func TestCompositeIntersectionSeconds(t *testing.T) {
+	// the seconds multiple of 10 and 15
+	s := NewIntersection(robfigResults(t, "*/10 * * * * *"), robfigResults(t, "*/15 * * * * *"))
+	from := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	expected := []time.Time{from.Add(30 * time.Second), from.Add(time.Minute)}
+	assert.Equal(t, expected, Upcoming(s, from, 2))
+}
+
+func TestCompositeDifference(t *testing.T) {
+	// weekdays at 9 except the 1st of the month
+	s := NewDifference(resultsFromString(t, "0 9 * * 1-5 cmd"), resultsFromString(t, "* * 1 * * cmd"))
+	// Sunday 28th of February 2021, Monday 1st of March is excluded
+	from := time.Date(2021, time.February, 28, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2021, time.March, 2, 9, 0, 0, 0, time.UTC), s.Next(from))
+	assert.False(t, s.Matches(time.Date(2021, time.March, 1, 9, 0, 0, 0, time.UTC)))
+	assert.True(t, s.Matches(time.Date(2021, time.March, 2, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestCompositeNested(t *testing.T) {
+	weekdays := resultsFromString(t, "0 9 * * 1-5 cmd")
+	weekends := resultsFromString(t, "0 10 * * 0,6 cmd")
+	s := NewDifference(NewUnion(weekdays, weekends), resultsFromString(t, "* * * 3 * cmd"))
+	from := time.Date(2021, time.February, 27, 12, 0, 0, 0, time.UTC)
+	expected := []time.Time{
+		time.Date(2021, time.February, 28, 10, 0, 0, 0, time.UTC),
+		time.Date(2021, time.April, 1, 9, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, expected, Upcoming(s, from, 2))
+	assert.Equal(t, "((0 9 * * 1-5) or (0 10 * * 0,6)) except (* * * 3 *)", s.String())
+}
+
+func TestBetween(t *testing.T) {
+	s := resultsFromString(t, "*/20 * * * * cmd")
+	start := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	expected := []time.Time{start, start.Add(20 * time.Minute), start.Add(40 * time.Minute)}
+	assert.Equal(t, expected, Between(s, start, start.Add(time.Hour)))
+}
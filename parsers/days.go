@@ -0,0 +1,175 @@
+package parsers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DayRuleKind is the kind of a day that depends on the month
+type DayRuleKind int
+
+const (
+	// LastDay is the last day of the month or Offset days before it (L and L-n)
+	LastDay DayRuleKind = iota
+	// LastWeekday is the last day from Monday to Friday of the month (LW)
+	LastWeekday
+	// NearestWeekday is the day from Monday to Friday closest to Day in the same month (nW)
+	NearestWeekday
+	// LastDayOfWeek is the last Weekday of the month (dL)
+	LastDayOfWeek
+	// NthDayOfWeek is the Nth Weekday of the month (d#n)
+	NthDayOfWeek
+)
+
+// DayRule is a day of the month that cannot be written as a list of days, as the L, W and # of Spring
+// and Quartz. The rules for the day of the month are LastDay, LastWeekday and NearestWeekday, the rules for
+// the day of the week are LastDayOfWeek and NthDayOfWeek
+type DayRule struct {
+	Kind    DayRuleKind
+	Day     int
+	Offset  int
+	Weekday time.Weekday
+	Nth     int
+}
+
+var (
+	dayMonthRule = regexp.MustCompile(`^L$|^L-([0-9]+)$|^LW$|^([0-9]+)W$`)
+	dayWeekRule  = regexp.MustCompile(`^([0-9])L$|^([0-9])#([0-9])$`)
+)
+
+// parseDayMonthRule returns the rule of a day of the month field, ok is false if the field is not a rule
+func parseDayMonthRule(field string) (rule DayRule, ok bool, err error) {
+	m := dayMonthRule.FindStringSubmatch(field)
+	if m == nil {
+		return rule, false, nil
+	}
+	switch {
+	case field == "L":
+		rule = DayRule{Kind: LastDay}
+	case field == "LW":
+		rule = DayRule{Kind: LastWeekday}
+	case m[1] != "":
+		offset, _ := strconv.Atoi(m[1])
+		if offset > 30 {
+			return rule, true, errors.New(fmt.Sprintf("Invalid offset from the last day of the month '%s'", field))
+		}
+		rule = DayRule{Kind: LastDay, Offset: offset}
+	default:
+		day, _ := strconv.Atoi(m[2])
+		if day < 1 || day > 31 {
+			return rule, true, errors.New(fmt.Sprintf("Invalid day for the nearest weekday '%s'", field))
+		}
+		rule = DayRule{Kind: NearestWeekday, Day: day}
+	}
+	return rule, true, nil
+}
+
+// parseDayWeekRule returns the rule of a day of the week field, ok is false if the field is not a rule
+func parseDayWeekRule(field string) (rule DayRule, ok bool, err error) {
+	m := dayWeekRule.FindStringSubmatch(field)
+	if m == nil {
+		return rule, false, nil
+	}
+	if m[1] != "" {
+		d, _ := strconv.Atoi(m[1])
+		rule = DayRule{Kind: LastDayOfWeek, Weekday: time.Weekday(d)}
+	} else {
+		d, _ := strconv.Atoi(m[2])
+		n, _ := strconv.Atoi(m[3])
+		if n < 1 || n > 5 {
+			return rule, true, errors.New(fmt.Sprintf("Invalid occurrence of the day of the week '%s'", field))
+		}
+		rule = DayRule{Kind: NthDayOfWeek, Weekday: time.Weekday(d), Nth: n}
+	}
+	if rule.Weekday > time.Saturday {
+		return rule, true, errors.New(fmt.Sprintf("Invalid day of the week '%s'", field))
+	}
+	return rule, true, nil
+}
+
+// Matches returns true if the day of t is the day of the rule
+func (r DayRule) Matches(t time.Time) bool {
+	last := daysIn(t)
+	switch r.Kind {
+	case LastDay:
+		return t.Day() == last-r.Offset
+	case LastWeekday:
+		day := last
+		for !isWeekday(time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC)) {
+			day--
+		}
+		return t.Day() == day
+	case NearestWeekday:
+		if r.Day > last {
+			return false
+		}
+		return t.Day() == nearestWeekday(t.Year(), t.Month(), r.Day, last)
+	case LastDayOfWeek:
+		return t.Weekday() == r.Weekday && t.Day()+7 > last
+	case NthDayOfWeek:
+		return t.Weekday() == r.Weekday && (t.Day()-1)/7+1 == r.Nth
+	}
+	return false
+}
+
+// String returns the rule in the syntax of Spring and Quartz
+func (r DayRule) String() string {
+	switch r.Kind {
+	case LastDay:
+		if r.Offset > 0 {
+			return fmt.Sprintf("L-%d", r.Offset)
+		}
+		return "L"
+	case LastWeekday:
+		return "LW"
+	case NearestWeekday:
+		return fmt.Sprintf("%dW", r.Day)
+	case LastDayOfWeek:
+		return fmt.Sprintf("%dL", r.Weekday)
+	case NthDayOfWeek:
+		return fmt.Sprintf("%d#%d", r.Weekday, r.Nth)
+	}
+	return ""
+}
+
+// nearestWeekday returns the day from Monday to Friday closest to day without leaving the month:
+// a Saturday moves to the Friday before unless it is the first day, a Sunday moves to the Monday
+// after unless it is the last day
+func nearestWeekday(year int, month time.Month, day int, last int) int {
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+// isWeekday returns true if t is from Monday to Friday
+func isWeekday(t time.Time) bool {
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// daysIn returns the number of days of the month of t
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// matchRules returns true if at least one of the rules matches t
+func matchRules(rules []DayRule, t time.Time) bool {
+	for _, r := range rules {
+		if r.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/expressions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDayRuleMatches(t *testing.T) {
+	tcs := []struct {
+		name    string
+		rule    string
+		weekday bool
+		days    []int
+	}{
+		// February 2021 starts on Monday and has 28 days
+		{"last day", "L", false, []int{28}},
+		{"before the last day", "L-2", false, []int{26}},
+		{"last weekday", "LW", false, []int{26}},
+		{"nearest weekday of saturday", "6W", false, []int{5}},
+		{"nearest weekday of sunday", "7W", false, []int{8}},
+		{"nearest weekday of the last sunday", "28W", false, []int{26}},
+		{"nearest weekday out of the month", "30W", false, []int{}},
+		{"last friday", "5L", true, []int{26}},
+		{"second monday", "1#2", true, []int{8}},
+		{"fifth monday", "1#5", true, []int{}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			parse := parseDayMonthRule
+			if tc.weekday {
+				parse = parseDayWeekRule
+			}
+			rule, ok, err := parse(tc.rule)
+			require.Nil(t, err)
+			require.True(t, ok)
+			assert.Equal(t, tc.rule, rule.String())
+			actual := []int{}
+			for d := 1; d <= 28; d++ {
+				if rule.Matches(time.Date(2021, time.February, d, 0, 0, 0, 0, time.UTC)) {
+					actual = append(actual, d)
+				}
+			}
+			assert.Equal(t, tc.days, actual)
+		})
+	}
+}
+
+func TestNearestWeekdayFirstSaturday(t *testing.T) {
+	// May 2021 starts on Saturday, the nearest weekday does not move to April
+	rule, _, err := parseDayMonthRule("1W")
+	require.Nil(t, err)
+	assert.True(t, rule.Matches(time.Date(2021, time.May, 3, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestDayRuleInvalid(t *testing.T) {
+	_, ok, err := parseDayMonthRule("L-31")
+	assert.True(t, ok)
+	assert.NotNil(t, err)
+	_, ok, err = parseDayMonthRule("0W")
+	assert.True(t, ok)
+	assert.NotNil(t, err)
+	_, ok, err = parseDayWeekRule("1#6")
+	assert.True(t, ok)
+	assert.NotNil(t, err)
+	_, ok, _ = parseDayWeekRule("1-5")
+	assert.False(t, ok)
+}
+
+func TestNextMatchBothDays(t *testing.T) {
+	holder, err := expressions.NewSpringSyntax("0 0 0 1 * MON")
+	require.Nil(t, err)
+	p, err := NewDefaultParser(holder)
+	require.Nil(t, err)
+	res, err := p.Results()
+	require.Nil(t, err)
+	// the 1st of the month that is also a Monday
+	assert.Equal(t, time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC),
+		res.Next(time.Date(2020, time.December, 1, 0, 0, 0, 0, time.UTC)))
+}
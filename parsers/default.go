@@ -7,9 +7,16 @@ import (
 	"github.com/reclaro/cep/utils"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// CronResults contains the results of the parsing of a cron expression
+// CronResults contains the results of the parsing of a cron expression.
+// Second is empty for the expressions without the seconds, in that case the expression runs at second 0.
+// Every is set instead of the time fields for the schedules that run at a constant interval.
+// Year is empty for the expressions that run every year.
+// DayMonthRules and DayWeekRules are the days that depend on the month (e.g. the last day of the month),
+// a day matches the day of the month if it is in DayMonth or in DayMonthRules and the same for the day of
+// the week. MatchBothDays requires a day to match both fields
 type CronResults struct {
 	Minute   []int
 	Hour     []int
@@ -17,6 +24,13 @@ type CronResults struct {
 	Month    []int
 	DayWeek  []int
 	Command  string
+	Second   []int
+	Every    time.Duration
+	Year     []int
+
+	DayMonthRules []DayRule
+	DayWeekRules  []DayRule
+	MatchBothDays bool
 }
 
 /*
@@ -33,12 +47,24 @@ type Parser interface {
 	Results() (*CronResults, error)
 }
 
+/*
+   FieldsParser is implemented by the parsers that also return the values of the optional fields of an
+   expression, the seconds and the years. The fields are empty if the expression does not have them
+*/
+type FieldsParser interface {
+	Parser
+	Seconds() ([]int, error)
+	Years() ([]int, error)
+}
+
 /* DefaultParser implements the Parser interface. The default parser follows the following rules
    minutes:  allowedValues 0-59
    hours:  allowed values 0-23
    days of the month: allowed values 1-31
    month: allowed Values 1-12
    day of the week: allowed Values 0-6. Sunday is the first day and it is reported as day 0
+   seconds: allowed values 0-59, only if the expression has the seconds
+   years: allowed values 1970-2199, only if the expression has the years
    In the DefaultParser the allowed values are continuos so are expressed as the min and max value
 */
 type DefaultParser struct {
@@ -52,6 +78,10 @@ type DefaultParser struct {
 	monthsInt []int
 	//Allowed values for days of the week
 	daysOfWeekInt []int
+	// Allowed values for seconds
+	secondsValues []int
+	// Allowed values for years
+	yearsValues []int
 	// A reference to the cron expression holder
 	holder expressions.Holder
 	// A field to keep the CronResults
@@ -68,6 +98,8 @@ func NewDefaultParser(expHolder expressions.Holder) (Parser, error) {
 		daysOfMonthValues: []int{1, 31},
 		monthsInt:         []int{1, 12},
 		daysOfWeekInt:     []int{0, 6},
+		secondsValues:     []int{0, 59},
+		yearsValues:       []int{1970, 2199},
 		holder:            expHolder,
 	}
 	ce, err := dp.holder.Elements()
@@ -171,6 +203,9 @@ func (dp *DefaultParser) manageSteps(input []string, allowedValues []int) ([]int
 	if errStep != nil {
 		return results, errors.New(fmt.Sprintf("Syntax error for step value"))
 	}
+	if step < 1 {
+		return results, errors.New(fmt.Sprintf("Invalid step value '%s', the step must be positive", input[1]))
+	}
 	var err error
 
 	// if we have an interval we take the start and end otherwise we have only a start
@@ -204,7 +239,22 @@ func (dp *DefaultParser) generateResults() error {
 	if dp.results == nil {
 		dp.results = &CronResults{}
 	}
-	_, err := dp.Minutes()
+	// a schedule with a constant interval does not have the time fields
+	if dp.cronElements.Every != "" {
+		every, err := time.ParseDuration(dp.cronElements.Every)
+		if err != nil || every <= 0 {
+			return errors.New(fmt.Sprintf("Invalid interval '%s'", dp.cronElements.Every))
+		}
+		dp.results.Every = every
+		_, err = dp.Command()
+		return err
+	}
+	dp.results.MatchBothDays = dp.cronElements.MatchBothDays
+	_, err := dp.Seconds()
+	if err != nil {
+		return err
+	}
+	_, err = dp.Minutes()
 	if err != nil {
 		return err
 	}
@@ -224,6 +274,10 @@ func (dp *DefaultParser) generateResults() error {
 	if err != nil {
 		return err
 	}
+	_, err = dp.Years()
+	if err != nil {
+		return err
+	}
 	_, err = dp.Command()
 	if err != nil {
 		return err
@@ -290,6 +344,16 @@ func (dp *DefaultParser) DaysOfTheMonth() ([]int, error) {
 	}
 
 	dom := dp.cronElements.DayMonth
+	// the days that depend on the month are the whole field, e.g. L or 15W
+	rule, ok, err := parseDayMonthRule(dom)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		dp.results.DayMonthRules = []DayRule{rule}
+		dp.results.DayMonth = []int{}
+		return dp.results.DayMonth, nil
+	}
 	dm, err := dp.parse(dom, dp.daysOfMonthValues)
 	if err != nil {
 		return nil, err
@@ -341,6 +405,16 @@ func (dp *DefaultParser) DaysOfTheWeek() ([]int, error) {
 	}
 
 	dow := dp.cronElements.DayWeek
+	// the days that depend on the month are the whole field, e.g. 5L or 1#2
+	rule, ok, err := parseDayWeekRule(dow)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		dp.results.DayWeekRules = []DayRule{rule}
+		dp.results.DayWeek = []int{}
+		return dp.results.DayWeek, nil
+	}
 	dw, err := dp.parse(dow, dp.daysOfWeekInt)
 	if err != nil {
 		return nil, err
@@ -355,6 +429,62 @@ func (dp *DefaultParser) DaysOfTheWeek() ([]int, error) {
 	return dw, nil
 }
 
+// Seconds return the list of values for seconds or an error, the list is empty if the expression
+// does not have the seconds
+func (dp *DefaultParser) Seconds() ([]int, error) {
+	if dp.results != nil && len(dp.results.Second) > 0 {
+		return dp.results.Second, nil
+	}
+
+	if dp.results == nil {
+		dp.results = &CronResults{}
+	}
+
+	secs := dp.cronElements.Second
+	if secs == "" {
+		return []int{}, nil
+	}
+	s, err := dp.parse(secs, dp.secondsValues)
+	if err != nil {
+		return nil, err
+	}
+	// The results are as an array of int without duplicates and in ascending order
+	dp.results.Second = utils.SortedUniqueInts(s)
+	// check if the values are in the allowed values, note that the check method requires a sorted array
+	if !dp.inAllowedValues(dp.results.Second, dp.secondsValues) {
+		return nil, errors.New(fmt.Sprintf("Second value is not in the allowed interval %v\n", dp.secondsValues))
+	}
+	return s, nil
+}
+
+// Years return the list of values for years or an error, the list is empty if the expression
+// runs every year
+func (dp *DefaultParser) Years() ([]int, error) {
+	if dp.results != nil && len(dp.results.Year) > 0 {
+		return dp.results.Year, nil
+	}
+
+	if dp.results == nil {
+		dp.results = &CronResults{}
+	}
+
+	ys := dp.cronElements.Year
+	if ys == "" || ys == "*" {
+		return []int{}, nil
+	}
+	y, err := dp.parse(ys, dp.yearsValues)
+	if err != nil {
+		return nil, err
+	}
+	// The results are as an array of int without duplicates and in ascending order
+	dp.results.Year = utils.SortedUniqueInts(y)
+	// check if the values are in the allowed values, note that the check method requires a sorted array
+	if !dp.inAllowedValues(dp.results.Year, dp.yearsValues) {
+		return nil, errors.New(fmt.Sprintf("Year value is not in the allowed interval %v\n", dp.yearsValues))
+	}
+	return y, nil
+}
+
 //Command returns the command field or an error
 func (dp *DefaultParser) Command() (string, error) {
 	if dp.results != nil && len(dp.results.Command) > 0 {
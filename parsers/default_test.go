@@ -79,6 +79,15 @@ func TestManageStepsInvalidStep(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestManageStepsZeroStep(t *testing.T) {
+	dp := defaultParserWithDefaultHolder(t)
+	allowedValues := []int{0, 59}
+	for _, input := range []string{"*/0", "1-19/0", "5/-1"} {
+		_, err := dp.manageSteps(strings.Split(input, "/"), allowedValues)
+		assert.NotNil(t, err, input)
+	}
+}
+
 func TestManageIntervals(t *testing.T) {
 	dp := defaultParserWithDefaultHolder(t)
 	expected := []int{2, 45}
@@ -320,6 +329,7 @@ func TestResultsInvalid(t *testing.T) {
 		{"Invalid days of month", "* * 99 * * /usr/bin/find"},
 		{"Invalid monts", "* * * 99 * /usr/bin/find"},
 		{"Invalid days of week", "* * * * 99 /usr/bin/find"},
+		{"Zero step", "*/0 * * * * /usr/bin/find"},
 	}
 
 	for _, tc := range tcs {
@@ -333,3 +343,18 @@ func TestResultsInvalid(t *testing.T) {
 		})
 	}
 }
+
+func TestFieldsParser(t *testing.T) {
+	holder, err := expressions.NewRobfigSyntax("30 */15 * * * *")
+	require.Nil(t, err)
+	p, err := NewDefaultParser(holder)
+	require.Nil(t, err)
+	fp, ok := p.(FieldsParser)
+	require.True(t, ok)
+	seconds, err := fp.Seconds()
+	require.Nil(t, err)
+	assert.Equal(t, []int{30}, seconds)
+	years, err := fp.Years()
+	require.Nil(t, err)
+	assert.Empty(t, years)
+}
@@ -0,0 +1,147 @@
+package parsers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DSTPolicy defines when a job runs if its wall clock time is skipped or repeated because of a change of
+// the offset of the time zone, e.g. the start and the end of the daylight saving time
+type DSTPolicy int
+
+const (
+	// DSTSkip does not run the jobs in the skipped hour, the jobs in the repeated hour run the first time
+	DSTSkip DSTPolicy = iota
+	// DSTRunOnce runs the jobs in the skipped hour once, right after the change of the offset. The jobs
+	// in the repeated hour run the first time
+	DSTRunOnce
+	// DSTRunTwice runs the jobs in the repeated hour both times, the jobs in the skipped hour do not run
+	DSTRunTwice
+	// DSTShiftForward runs the jobs in the skipped hour later by the size of the change, e.g. 02:30 runs
+	// at 03:30. The jobs in the repeated hour run the first time
+	DSTShiftForward
+)
+
+// dstPolicies maps the names of the policies
+var dstPolicies = map[string]DSTPolicy{"skip": DSTSkip, "once": DSTRunOnce, "twice": DSTRunTwice, "shift": DSTShiftForward}
+
+// maxDSTChange is bigger than any change of the offset of a time zone
+const maxDSTChange = 3 * time.Hour
+
+// ParseDSTPolicy returns the policy with the given name: skip, once, twice or shift
+func ParseDSTPolicy(name string) (DSTPolicy, error) {
+	p, ok := dstPolicies[name]
+	if !ok {
+		return DSTSkip, errors.New(fmt.Sprintf("Unknown DST policy '%s', allowed values are skip, once, twice and shift", name))
+	}
+	return p, nil
+}
+
+// String returns the name of the policy
+func (p DSTPolicy) String() string {
+	for name, v := range dstPolicies {
+		if v == p {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+/*
+Resolve returns the instants when a job scheduled at the wall clock of w runs in the location loc.
+Only the date and the time of w are used, not its location.
+There are no instants if the wall clock is skipped and the policy does not run the job, two instants
+if the wall clock is repeated and the policy runs the job twice.
+*/
+func (p DSTPolicy) Resolve(w time.Time, loc *time.Location) []time.Time {
+	naive := time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), 0, 0, time.UTC)
+	// the offsets before and after a possible change, around the instant of the wall clock
+	_, offset := naive.In(loc).Zone()
+	approx := naive.Add(-time.Duration(offset) * time.Second)
+	_, before := approx.Add(-maxDSTChange).In(loc).Zone()
+	_, after := approx.Add(maxDSTChange).In(loc).Zone()
+	res := []time.Time{}
+	for _, offset := range []int{before, after} {
+		t := naive.Add(-time.Duration(offset) * time.Second).In(loc)
+		if sameWallClock(t, naive) && (len(res) == 0 || !res[0].Equal(t)) {
+			res = append(res, t)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Before(res[j]) })
+
+	switch {
+	case len(res) == 2 && p != DSTRunTwice:
+		return res[:1]
+	case len(res) == 0 && p == DSTShiftForward:
+		return []time.Time{naive.Add(-time.Duration(before) * time.Second).In(loc)}
+	case len(res) == 0 && p == DSTRunOnce:
+		// the first instant with the new offset, it is between the wall clock read with the new offset
+		// and the wall clock read with the old one
+		lo := naive.Add(-time.Duration(after) * time.Second)
+		hi := naive.Add(-time.Duration(before) * time.Second)
+		for hi.Sub(lo) > time.Minute {
+			mid := lo.Add((hi.Sub(lo) / 2).Truncate(time.Minute))
+			if _, o := mid.In(loc).Zone(); o == before {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		return []time.Time{hi.In(loc)}
+	}
+	return res
+}
+
+// sameWallClock returns true if the two times have the same date, hour and minute
+func sameWallClock(a time.Time, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day() && a.Hour() == b.Hour() && a.Minute() == b.Minute()
+}
+
+/*
+DSTSchedule is a cron expression that follows a DSTPolicy when the offset of the time zone changes.
+The runs are computed on the wall clock, then every run is resolved to the instants of the location
+of the time passed to Next and Matches.
+*/
+type DSTSchedule struct {
+	Results *CronResults
+	Policy  DSTPolicy
+}
+
+// NewDSTSchedule returns the cron expression with the given DST policy
+func NewDSTSchedule(cr *CronResults, policy DSTPolicy) *DSTSchedule {
+	return &DSTSchedule{Results: cr, Policy: policy}
+}
+
+// Next returns the first run after t or the zero time if there are no runs in the next MaxYears years
+func (d *DSTSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	// the instants are not in the order of the wall clock around a change of the offset, so we look at
+	// the wall clock from a bit before t until we are sure that there cannot be an earlier instant
+	best, bestWall := time.Time{}, time.Time{}
+	for w := d.Results.Next(wall.Add(-maxDSTChange)); !w.IsZero(); w = d.Results.Next(w) {
+		if !best.IsZero() && w.After(bestWall.Add(maxDSTChange)) {
+			break
+		}
+		for _, run := range d.Policy.Resolve(w, loc) {
+			if run.After(t) && (best.IsZero() || run.Before(best)) {
+				best = run
+				bestWall = time.Date(run.Year(), run.Month(), run.Day(), run.Hour(), run.Minute(), 0, 0, time.UTC)
+			}
+		}
+	}
+	return best
+}
+
+// Matches returns true if the schedule runs in the minute of t
+func (d *DSTSchedule) Matches(t time.Time) bool {
+	t = t.Truncate(time.Minute)
+	return d.Next(t.Add(-time.Minute)).Equal(t)
+}
+
+// String returns the description of the schedule
+func (d *DSTSchedule) String() string {
+	return fmt.Sprintf("%s with DST policy %s", Describe(d.Results), d.Policy)
+}
@@ -0,0 +1,91 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func berlin(t *testing.T) *time.Location {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+	return loc
+}
+
+func TestResolve(t *testing.T) {
+	loc := berlin(t)
+	// the 28th of March 2021 at 02:00 the clock moves to 03:00
+	gap := time.Date(2021, time.March, 28, 2, 30, 0, 0, time.UTC)
+	// the 31st of October 2021 at 03:00 the clock moves back to 02:00
+	repeated := time.Date(2021, time.October, 31, 2, 30, 0, 0, time.UTC)
+	normal := time.Date(2021, time.October, 30, 2, 30, 0, 0, time.UTC)
+
+	tcs := []struct {
+		name     string
+		policy   DSTPolicy
+		wall     time.Time
+		expected []time.Time
+	}{
+		{"skip gap", DSTSkip, gap, []time.Time{}},
+		{"run once gap", DSTRunOnce, gap, []time.Time{time.Date(2021, time.March, 28, 1, 0, 0, 0, time.UTC)}},
+		{"run twice gap", DSTRunTwice, gap, []time.Time{}},
+		{"shift gap", DSTShiftForward, gap, []time.Time{time.Date(2021, time.March, 28, 1, 30, 0, 0, time.UTC)}},
+		{"skip repeated", DSTSkip, repeated, []time.Time{time.Date(2021, time.October, 31, 0, 30, 0, 0, time.UTC)}},
+		{"run twice repeated", DSTRunTwice, repeated, []time.Time{
+			time.Date(2021, time.October, 31, 0, 30, 0, 0, time.UTC),
+			time.Date(2021, time.October, 31, 1, 30, 0, 0, time.UTC),
+		}},
+		{"normal", DSTRunTwice, normal, []time.Time{time.Date(2021, time.October, 30, 0, 30, 0, 0, time.UTC)}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tc.policy.Resolve(tc.wall, loc)
+			require.Len(t, actual, len(tc.expected))
+			for i := range tc.expected {
+				assert.True(t, tc.expected[i].Equal(actual[i]), "expected %s, actual %s", tc.expected[i], actual[i])
+			}
+		})
+	}
+}
+
+func TestDSTScheduleNext(t *testing.T) {
+	loc := berlin(t)
+	cr := resultsFromString(t, "30 2 * * * cmd")
+	from := time.Date(2021, time.March, 27, 12, 0, 0, 0, loc)
+
+	// with the skip policy there is no run on the 28th of March
+	runs := Upcoming(NewDSTSchedule(cr, DSTSkip), from, 1)
+	assert.Equal(t, "2021-03-29 02:30 CEST", runs[0].Format("2006-01-02 15:04 MST"))
+
+	runs = Upcoming(NewDSTSchedule(cr, DSTShiftForward), from, 2)
+	assert.Equal(t, "2021-03-28 03:30 CEST", runs[0].Format("2006-01-02 15:04 MST"))
+	assert.Equal(t, "2021-03-29 02:30 CEST", runs[1].Format("2006-01-02 15:04 MST"))
+
+	// every 20 minutes in the repeated hour
+	cr = resultsFromString(t, "*/20 2 * * * cmd")
+	from = time.Date(2021, time.October, 31, 1, 59, 0, 0, loc)
+	formatted := []string{}
+	for _, r := range Upcoming(NewDSTSchedule(cr, DSTRunTwice), from, 7) {
+		formatted = append(formatted, r.Format("15:04 MST"))
+	}
+	expected := []string{"02:00 CEST", "02:20 CEST", "02:40 CEST", "02:00 CET", "02:20 CET", "02:40 CET", "02:00 CET"}
+	assert.Equal(t, expected, formatted)
+
+	s := NewDSTSchedule(cr, DSTRunTwice)
+	// 02:20 CET, the second time the clock shows 02:20
+	second := time.Date(2021, time.October, 31, 1, 20, 0, 0, time.UTC).In(loc)
+	assert.True(t, s.Matches(second))
+	assert.False(t, NewDSTSchedule(cr, DSTSkip).Matches(second))
+}
+
+func TestParseDSTPolicy(t *testing.T) {
+	p, err := ParseDSTPolicy("twice")
+	assert.Nil(t, err)
+	assert.Equal(t, DSTRunTwice, p)
+	assert.Equal(t, "twice", p.String())
+	_, err = ParseDSTPolicy("never")
+	assert.NotNil(t, err)
+}
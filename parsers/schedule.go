@@ -0,0 +1,235 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/utils"
+)
+
+// MaxYears is the number of years after which Next gives up looking for the next run, this happens
+// for expressions that never run such as the 30th of February
+const MaxYears = 5
+
+/*
+Schedule defines the methods of anything that can tell when a job runs.
+Next returns the first time strictly after the input time when the job runs or the zero time if there
+is no such time. Matches returns true if the job runs at the input time.
+Both methods work with a precision of one minute, or one second for the expressions with the seconds,
+and they use the location of the input time.
+*/
+type Schedule interface {
+	Next(time.Time) time.Time
+	Matches(time.Time) bool
+}
+
+// Upcoming returns the next n runs of the schedule after t, it returns less than n runs if the
+// schedule stops running
+func Upcoming(s Schedule, t time.Time, n int) []time.Time {
+	res := []time.Time{}
+	for len(res) < n {
+		t = s.Next(t)
+		if t.IsZero() {
+			break
+		}
+		res = append(res, t)
+	}
+	return res
+}
+
+// Between returns the runs of the schedule from start (included) to end (excluded)
+func Between(s Schedule, start time.Time, end time.Time) []time.Time {
+	res := []time.Time{}
+	for t := from(s, start); !t.IsZero() && t.Before(end); t = s.Next(t) {
+		res = append(res, t)
+	}
+	return res
+}
+
+// from returns the first run of the schedule at t or after it. The schedules can have the precision of
+// a second, so the run at t is the next run of the instant right before t
+func from(s Schedule, t time.Time) time.Time {
+	if n := s.Next(t.Add(-time.Nanosecond)); n.Equal(t) {
+		return n
+	}
+	return s.Next(t)
+}
+
+// Describe returns a description of a schedule: schedules that implement fmt.Stringer describe
+// themselves, CronResults are described by their time fields
+func Describe(s Schedule) string {
+	switch v := s.(type) {
+	case fmt.Stringer:
+		return v.String()
+	case *CronResults:
+		if v.Every > 0 {
+			return "@every " + v.Every.String()
+		}
+		fields := []string{}
+		if len(v.Second) > 0 {
+			fields = append(fields, utils.CompactValues(v.Second, []int{0, 59}))
+		}
+		fields = append(fields,
+			utils.CompactValues(v.Minute, []int{0, 59}),
+			utils.CompactValues(v.Hour, []int{0, 23}),
+			dayField(v.DayMonth, v.DayMonthRules, []int{1, 31}),
+			utils.CompactValues(v.Month, []int{1, 12}),
+			dayField(v.DayWeek, v.DayWeekRules, []int{0, 6}),
+		)
+		if len(v.Year) > 0 {
+			fields = append(fields, utils.CompactValues(v.Year, []int{1970, 2199}))
+		}
+		return strings.Join(fields, " ")
+	}
+	return fmt.Sprintf("%v", s)
+}
+
+// dayField describes the values and the rules of a day field
+func dayField(values []int, rules []DayRule, allowedValues []int) string {
+	res := []string{}
+	if len(values) > 0 {
+		res = append(res, utils.CompactValues(values, allowedValues))
+	}
+	for _, r := range rules {
+		res = append(res, r.String())
+	}
+	return strings.Join(res, ",")
+}
+
+// date returns the time of the wall clock in the location or, when a change of the offset skips the wall
+// clock, the first time after it. time.Date can normalize a skipped wall clock to a time before it, e.g.
+// the midnight skipped in America/Santiago becomes 23:00 of the day before
+func date(year int, month time.Month, day, hour, min, sec int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, min, sec, 0, loc)
+	wall := time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	got := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	if got.Before(wall) {
+		// t has the offset before the change, moving it by the missing time reaches the end of the gap
+		t = t.Add(wall.Sub(got))
+	}
+	return t
+}
+
+// Next returns the first minute after t when the expression runs or the zero time if the expression
+// does not run in the next MaxYears years. A schedule with a constant interval runs after the interval
+// has passed, as in robfig/cron the interval is counted from t rounded down to the second
+func (cr *CronResults) Next(t time.Time) time.Time {
+	if cr.Every > 0 {
+		return t.Add(cr.Every - time.Duration(t.Nanosecond()))
+	}
+	// the expressions without the seconds run at second 0
+	seconds := cr.Second
+	if len(seconds) == 0 {
+		seconds = []int{0}
+	}
+	loc := t.Location()
+	t = date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()+1, loc)
+	yearLimit := t.Year() + MaxYears
+
+	// Every time a field wraps around we need to check again the bigger fields, for example moving to
+	// the next day can move to the next month. The wrap is found comparing the fields before and after
+	// the move, a change of the offset can skip the first hour of a day
+WRAP:
+	if len(cr.Year) > 0 && !contains(cr.Year, t.Year()) {
+		year := nextYear(cr.Year, t.Year())
+		if year == 0 {
+			return time.Time{}
+		}
+		t = date(year, time.January, 1, 0, 0, 0, loc)
+		yearLimit = year + MaxYears
+	}
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+	for !contains(cr.Month, int(t.Month())) {
+		prev := t
+		t = date(t.Year(), t.Month()+1, 1, 0, 0, 0, loc)
+		if t.Year() != prev.Year() {
+			goto WRAP
+		}
+	}
+	for !cr.matchDay(t) {
+		prev := t
+		t = date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, loc)
+		if t.Month() != prev.Month() {
+			goto WRAP
+		}
+	}
+	for !contains(cr.Hour, t.Hour()) {
+		prev := t
+		t = date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, loc)
+		if t.Day() != prev.Day() {
+			goto WRAP
+		}
+	}
+	for !contains(cr.Minute, t.Minute()) {
+		prev := t
+		t = date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, loc)
+		if t.Hour() != prev.Hour() {
+			goto WRAP
+		}
+	}
+	for !contains(seconds, t.Second()) {
+		prev := t
+		t = date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()+1, loc)
+		if t.Minute() != prev.Minute() {
+			goto WRAP
+		}
+	}
+	return t
+}
+
+// Matches returns true if the expression runs in the minute of t, or in the second of t for the
+// expressions with the seconds. A schedule with a constant interval does not run at fixed times so it
+// never matches
+func (cr *CronResults) Matches(t time.Time) bool {
+	if cr.Every > 0 {
+		return false
+	}
+	if len(cr.Second) > 0 && !contains(cr.Second, t.Second()) {
+		return false
+	}
+	if len(cr.Year) > 0 && !contains(cr.Year, t.Year()) {
+		return false
+	}
+	return contains(cr.Minute, t.Minute()) &&
+		contains(cr.Hour, t.Hour()) &&
+		contains(cr.Month, int(t.Month())) &&
+		cr.matchDay(t)
+}
+
+/*
+matchDay checks the day of the month and the day of the week of t.
+As in Vixie cron, when both fields are restricted (they are not all the allowed values) the day matches
+if at least one of the two fields matches, otherwise both fields must match. The expressions with
+MatchBothDays always require both fields to match.
+*/
+func (cr *CronResults) matchDay(t time.Time) bool {
+	dom := contains(cr.DayMonth, t.Day()) || matchRules(cr.DayMonthRules, t)
+	dow := contains(cr.DayWeek, int(t.Weekday())) || matchRules(cr.DayWeekRules, t)
+	if !cr.MatchBothDays && len(cr.DayMonth) < 31 && len(cr.DayWeek) < 7 {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// nextYear returns the first year in the sorted input after year or 0 if there is no such year
+func nextYear(years []int, year int) int {
+	for _, y := range years {
+		if y > year {
+			return y
+		}
+	}
+	return 0
+}
+
+// contains returns true if the value is in the input
+func contains(input []int, value int) bool {
+	for _, v := range input {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package parsers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/expressions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resultsFromString(t *testing.T, input string) *CronResults {
+	dp := defaultParserWithDefaultHolderWithString(t, input)
+	res, err := dp.Results()
+	require.Nil(t, err)
+	return res
+}
+
+// robfigResults expands a robfig/cron spec
+func robfigResults(t *testing.T, input string) *CronResults {
+	holder, err := expressions.NewRobfigSyntax(input)
+	require.Nil(t, err)
+	p, err := NewDefaultParser(holder)
+	require.Nil(t, err)
+	res, err := p.Results()
+	require.Nil(t, err)
+	return res
+}
+
+func TestNext(t *testing.T) {
+	from := time.Date(2021, time.March, 31, 23, 50, 30, 0, time.UTC)
+	tcs := []struct {
+		name     string
+		input    string
+		expected time.Time
+	}{
+		{"every minute", "* * * * * cmd", time.Date(2021, time.March, 31, 23, 51, 0, 0, time.UTC)},
+		{"next hour", "*/15 0 * * * cmd", time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"next year", "0 0 1 1 * cmd", time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"day of week", "30 9 * * 1 cmd", time.Date(2021, time.April, 5, 9, 30, 0, 0, time.UTC)},
+		{"day of month or day of week", "0 12 15 * 5 cmd", time.Date(2021, time.April, 2, 12, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 * cmd", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"never", "0 0 30 2 * cmd", time.Time{}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			res := resultsFromString(t, tc.input)
+			assert.Equal(t, tc.expected, res.Next(from))
+		})
+	}
+}
+
+func TestNextSkippedMidnight(t *testing.T) {
+	// on the 11th of September 2022 the clocks of America/Santiago go from 23:59:59 to 01:00
+	loc, err := time.LoadLocation("America/Santiago")
+	require.Nil(t, err)
+	from := time.Date(2022, time.September, 10, 2, 0, 0, 0, loc)
+	tcs := []struct {
+		name     string
+		input    string
+		expected []time.Time
+	}{
+		{"every day", "30 12 * * * cmd", []time.Time{
+			time.Date(2022, time.September, 10, 12, 30, 0, 0, loc),
+			time.Date(2022, time.September, 11, 12, 30, 0, 0, loc),
+		}},
+		{"skipped hour", "0 0,1 * * * cmd", []time.Time{
+			time.Date(2022, time.September, 11, 1, 0, 0, 0, loc),
+			time.Date(2022, time.September, 12, 0, 0, 0, 0, loc),
+		}},
+		{"day of the change", "0 * 11 * * cmd", []time.Time{
+			time.Date(2022, time.September, 11, 1, 0, 0, 0, loc),
+			time.Date(2022, time.September, 11, 2, 0, 0, 0, loc),
+		}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			res := resultsFromString(t, tc.input)
+			assert.Equal(t, tc.expected, Upcoming(res, from, 2))
+		})
+	}
+}
+
+func TestMatches(t *testing.T) {
+	res := resultsFromString(t, "*/15 0 1,15 * 1-5 cmd")
+	// Monday 1st of March 2021
+	assert.True(t, res.Matches(time.Date(2021, time.March, 1, 0, 45, 0, 0, time.UTC)))
+	// Saturday 6th of March 2021, neither the day of the month nor the day of the week matches
+	assert.False(t, res.Matches(time.Date(2021, time.March, 6, 0, 45, 0, 0, time.UTC)))
+	// Friday 5th of March 2021, both day fields are restricted so the day of the week is enough
+	assert.True(t, res.Matches(time.Date(2021, time.March, 5, 0, 30, 0, 0, time.UTC)))
+	assert.False(t, res.Matches(time.Date(2021, time.March, 1, 1, 0, 0, 0, time.UTC)))
+}
+
+func TestNextSeconds(t *testing.T) {
+	from := time.Date(2021, time.March, 31, 23, 59, 50, 0, time.UTC)
+	tcs := []struct {
+		name     string
+		input    string
+		expected time.Time
+	}{
+		{"every second", "* * * * * *", time.Date(2021, time.March, 31, 23, 59, 51, 0, time.UTC)},
+		{"next minute", "*/20 * * * * *", time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"same minute", "55 * * * * *", time.Date(2021, time.March, 31, 23, 59, 55, 0, time.UTC)},
+		{"next day", "30 0 9 * * *", time.Date(2021, time.April, 1, 9, 0, 30, 0, time.UTC)},
+		{"no seconds", "0 9 * * *", time.Date(2021, time.April, 1, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			res := robfigResults(t, tc.input)
+			assert.Equal(t, tc.expected, res.Next(from))
+		})
+	}
+}
+
+func TestNextEvery(t *testing.T) {
+	res := robfigResults(t, "@every 1h30m")
+	from := time.Date(2021, time.March, 31, 23, 50, 30, 500, time.UTC)
+	assert.Equal(t, []time.Time{
+		time.Date(2021, time.April, 1, 1, 20, 30, 0, time.UTC),
+		time.Date(2021, time.April, 1, 2, 50, 30, 0, time.UTC),
+	}, Upcoming(res, from, 2))
+	assert.False(t, res.Matches(time.Date(2021, time.April, 1, 1, 20, 30, 0, time.UTC)))
+	assert.Equal(t, "@every 1h30m0s", Describe(res))
+}
+
+func TestBetweenSeconds(t *testing.T) {
+	res := robfigResults(t, "*/10 * * * * *")
+	start := time.Date(2021, time.March, 1, 0, 0, 30, 0, time.UTC)
+	expected := []time.Time{start, start.Add(10 * time.Second), start.Add(20 * time.Second)}
+	assert.Equal(t, expected, Between(res, start, start.Add(30*time.Second)))
+	// a start between two runs
+	assert.Equal(t, expected[1:], Between(res, start.Add(time.Millisecond), start.Add(30*time.Second)))
+
+	every := robfigResults(t, "@every 10s")
+	assert.Equal(t, expected[1:], Between(every, start, start.Add(30*time.Second)))
+}
+
+func TestMatchesSeconds(t *testing.T) {
+	res := robfigResults(t, "15,45 0 9 * * MON")
+	assert.True(t, res.Matches(time.Date(2021, time.March, 1, 9, 0, 45, 0, time.UTC)))
+	assert.False(t, res.Matches(time.Date(2021, time.March, 1, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "15,45 0 9 * * 1", Describe(res))
+}
+
+func TestNextYears(t *testing.T) {
+	holder, err := expressions.NewQuartzSyntax("0 0 9 L 2 ? 2023,2028")
+	require.Nil(t, err)
+	p, err := NewDefaultParser(holder)
+	require.Nil(t, err)
+	res, err := p.Results()
+	require.Nil(t, err)
+	from := time.Date(2021, time.March, 31, 23, 50, 30, 0, time.UTC)
+	assert.Equal(t, []time.Time{
+		time.Date(2023, time.February, 28, 9, 0, 0, 0, time.UTC),
+		time.Date(2028, time.February, 29, 9, 0, 0, 0, time.UTC),
+	}, Upcoming(res, from, 3))
+	assert.False(t, res.Matches(time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "0 0 9 L 2 * 2023,2028", Describe(res))
+}
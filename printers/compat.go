@@ -0,0 +1,32 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/reclaro/cep/expressions"
+)
+
+// CompatReport prints a line for every dialect with the dialect name, if it accepts the expression and
+// the description of the dialect or the reason why it rejects the expression
+type CompatReport struct {
+	out io.Writer
+}
+
+// NewCompatReport returns a printer for the compatibility of an expression that writes on the standard output
+func NewCompatReport() *CompatReport {
+	return &CompatReport{out: os.Stdout}
+}
+
+// Print prints the compatibility with every dialect
+func (p *CompatReport) Print(res []expressions.Compatibility) {
+	for _, c := range res {
+		if c.Err == nil {
+			fmt.Fprintf(p.out, "%-12s%-10s%s\n", c.Dialect.Name, "accepted", c.Dialect.Description)
+			continue
+		}
+		fmt.Fprintf(p.out, "%-12s%-10s%s\n", c.Dialect.Name, "rejected", strings.TrimSpace(c.Err.Error()))
+	}
+}
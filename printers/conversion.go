@@ -0,0 +1,33 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/reclaro/cep/converters"
+)
+
+// ConversionReport prints the result of a conversion: the converted expressions, one per line, and
+// a warning for every difference with the input or for every construct that cannot be converted. The
+// warnings are written apart so that the output can be used as it is, e.g. in a crontab file
+type ConversionReport struct {
+	out io.Writer
+	err io.Writer
+}
+
+// NewConversionReport returns a printer for conversions that writes the expressions on the standard output
+// and the warnings on the standard error
+func NewConversionReport() *ConversionReport {
+	return &ConversionReport{out: os.Stdout, err: os.Stderr}
+}
+
+// Print prints the conversion
+func (p *ConversionReport) Print(c *converters.Conversion) {
+	for _, e := range c.Expressions {
+		fmt.Fprintln(p.out, e)
+	}
+	for _, w := range c.Warnings {
+		fmt.Fprintf(p.err, "warning: %s\n", w)
+	}
+}
@@ -0,0 +1,21 @@
+package printers
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/reclaro/cep/converters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversionReport(t *testing.T) {
+	var out, errOut bytes.Buffer
+	prt := &ConversionReport{out: &out, err: &errOut}
+	prt.Print(&converters.Conversion{
+		Expressions: []string{"0 9 * * 1-5"},
+		Lossy:       true,
+		Warnings:    []string{"the years are dropped"},
+	})
+	assert.Equal(t, "0 9 * * 1-5\n", out.String())
+	assert.Equal(t, "warning: the years are dropped\n", errOut.String())
+}
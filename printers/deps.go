@@ -0,0 +1,72 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/reclaro/cep/daemon"
+)
+
+// DependencyReport prints a line for every job in the order of the dependencies, with the jobs it runs
+// after and its window
+type DependencyReport struct {
+	out io.Writer
+}
+
+// NewDependencyReport returns a printer for the dependencies of the jobs that writes on the standard output
+func NewDependencyReport() *DependencyReport {
+	return &DependencyReport{out: os.Stdout}
+}
+
+// Print prints the jobs and their dependencies
+func (p *DependencyReport) Print(g *daemon.Graph) {
+	width := 0
+	for _, name := range g.Jobs {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for _, name := range g.Jobs {
+		line := fmt.Sprintf("%-*s", width, name)
+		if after := g.After[name]; len(after) > 0 {
+			line += "  after " + strings.Join(after, ", ")
+		}
+		if w, ok := g.Windows[name]; ok {
+			line += ", within " + w
+		}
+		fmt.Fprintln(p.out, strings.TrimRight(line, " "))
+	}
+}
+
+// DependencyDOT prints the graph of the dependencies in the DOT language of Graphviz, e.g. to draw it with
+// dot -Tsvg
+type DependencyDOT struct {
+	out io.Writer
+}
+
+// NewDependencyDOT returns a printer for the graph of the dependencies that writes on the standard output
+func NewDependencyDOT() *DependencyDOT {
+	return &DependencyDOT{out: os.Stdout}
+}
+
+// Print prints the graph, the jobs with a window have it in their label
+func (p *DependencyDOT) Print(g *daemon.Graph) {
+	fmt.Fprintln(p.out, "digraph jobs {")
+	fmt.Fprintln(p.out, "  rankdir=LR;")
+	for _, name := range g.Jobs {
+		label := name
+		if w, ok := g.Windows[name]; ok {
+			label += "\n" + w
+		}
+		fmt.Fprintf(p.out, "  %s [label=%s];\n", strconv.Quote(name), strconv.Quote(label))
+	}
+	for _, name := range g.Jobs {
+		for _, d := range g.Dependents[name] {
+			fmt.Fprintf(p.out, "  %s -> %s;\n", strconv.Quote(name), strconv.Quote(d))
+		}
+	}
+	fmt.Fprintln(p.out, "}")
+}
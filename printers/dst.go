@@ -0,0 +1,46 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/reclaro/cep/analysis"
+)
+
+// DSTReport prints the runs affected by the changes of the offset of a time zone, for every run it
+// prints when the job runs with every DST policy
+type DSTReport struct {
+	out io.Writer
+}
+
+// NewDSTReport returns a printer for the DST report that writes on the standard output
+func NewDSTReport() *DSTReport {
+	return &DSTReport{out: os.Stdout}
+}
+
+// Print prints the DST report
+func (p *DSTReport) Print(events []analysis.DSTEvent) {
+	if len(events) == 0 {
+		fmt.Fprintln(p.out, "no runs affected by DST transitions")
+		return
+	}
+	for _, e := range events {
+		kind := "repeated"
+		if e.Skipped {
+			kind = "skipped"
+		}
+		fmt.Fprintf(p.out, "%s %-8s (transition at %s)\n", e.Wall.Format("2006-01-02 15:04"), kind, e.Transition.Format(runFormat))
+		for _, policy := range analysis.DSTPolicies {
+			runs := []string{}
+			for _, r := range e.Runs[policy] {
+				runs = append(runs, r.Format("15:04 MST"))
+			}
+			if len(runs) == 0 {
+				runs = append(runs, "not run")
+			}
+			fmt.Fprintf(p.out, "  %-7s%s\n", policy, strings.Join(runs, ", "))
+		}
+	}
+}
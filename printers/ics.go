@@ -0,0 +1,167 @@
+package printers
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+	"github.com/reclaro/cep/utils"
+)
+
+// Formats of the times in the iCalendar output
+const (
+	icsLocalTime = "20060102T150405"
+	icsUTCTime   = "20060102T150405Z"
+	// icsLineLength is the max length in bytes of a line, longer lines are folded
+	icsLineLength = 75
+)
+
+/*
+ICS prints the runs of a schedule as an iCalendar file (RFC 5545) with a VEVENT for every run.
+The summary of the events is the command. The times are written in UTC when the location of the runs
+is UTC or it has no IANA name, e.g. the local time zone or a fixed offset, otherwise they use a TZID and
+the file contains the VTIMEZONE with the transitions of the location in the period of the runs.
+The UID of an event depends only on the schedule, the command and the time of the run, so exporting
+again the same schedule updates the events in a calendar instead of duplicating them.
+*/
+type ICS struct {
+	out io.Writer
+	// duration is the duration of every event
+	duration time.Duration
+	// now returns the time used for DTSTAMP
+	now func() time.Time
+}
+
+// NewICS returns an iCalendar printer that writes on the standard output, every event lasts duration
+func NewICS(duration time.Duration) RunsPrinter {
+	return &ICS{out: os.Stdout, duration: duration, now: time.Now}
+}
+
+// PrintRuns prints the calendar with an event for every run
+func (p *ICS) PrintRuns(s parsers.Schedule, command string, runs []time.Time) {
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//reclaro//cep//EN", "CALSCALE:GREGORIAN"}
+	if len(runs) > 0 && !hasTZID(runs[0].Location()) {
+		// the calendar clients cannot resolve the zone, the same instants are written in UTC
+		utc := make([]time.Time, len(runs))
+		for i, t := range runs {
+			utc[i] = t.UTC()
+		}
+		runs = utc
+	}
+	if len(runs) > 0 && runs[0].Location() != time.UTC {
+		lines = append(lines, vtimezone(runs[0].Location(), runs[0], runs[len(runs)-1].Add(p.duration))...)
+	}
+	id := sha1.Sum([]byte(parsers.Describe(s) + "\n" + command))
+	stamp := p.now().UTC().Format(icsUTCTime)
+	for _, t := range runs {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			fmt.Sprintf("UID:%x-%s@cep", id[:8], t.UTC().Format(icsUTCTime)),
+			"DTSTAMP:"+stamp,
+			"DTSTART"+icsTime(t),
+			"DTEND"+icsTime(t.Add(p.duration)),
+			"SUMMARY:"+icsText(command),
+			"DESCRIPTION:"+icsText(parsers.Describe(s)),
+			"END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+	for _, l := range lines {
+		fmt.Fprint(p.out, fold(l))
+	}
+}
+
+// icsTime returns the parameters and the value of a time property
+func icsTime(t time.Time) string {
+	if t.Location() == time.UTC {
+		return ":" + t.Format(icsUTCTime)
+	}
+	return fmt.Sprintf(";TZID=%s:%s", t.Location().String(), t.Format(icsLocalTime))
+}
+
+// hasTZID tells if the name of the location is an IANA name that can be used as TZID, the local
+// location is named Local whatever its zone is
+func hasTZID(loc *time.Location) bool {
+	if loc == time.UTC || loc == time.Local || loc.String() == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(loc.String())
+	return err == nil
+}
+
+// icsText escapes the characters that have a special meaning in the text values
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// fold splits a line longer than icsLineLength bytes in multiple lines, every continuation line starts
+// with a space. The line is terminated by CRLF
+func fold(line string) string {
+	var b strings.Builder
+	limit := icsLineLength
+	for len(line) > limit {
+		// we don't split a multi byte character
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = icsLineLength - 1
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
+
+// vtimezone returns the VTIMEZONE component of the location with the offset at start and all the
+// transitions from start to end
+func vtimezone(loc *time.Location, start time.Time, end time.Time) []string {
+	lines := []string{"BEGIN:VTIMEZONE", "TZID:" + loc.String()}
+	_, offset := start.Zone()
+	lines = append(lines, observance(loc, start, offset)...)
+	for _, t := range utils.Transitions(start, end) {
+		lines = append(lines, observance(loc, t, offset)...)
+		_, offset = t.Zone()
+	}
+	return append(lines, "END:VTIMEZONE")
+}
+
+// observance returns the STANDARD or DAYLIGHT component for the offset that starts at t, from is the
+// offset before t
+func observance(loc *time.Location, t time.Time, from int) []string {
+	name, offset := t.Zone()
+	kind := "STANDARD"
+	if isDaylight(loc, t) {
+		kind = "DAYLIGHT"
+	}
+	return []string{
+		"BEGIN:" + kind,
+		// the start of an observance is the wall clock before the change
+		"DTSTART:" + t.In(time.FixedZone("", from)).Format(icsLocalTime),
+		"TZOFFSETFROM:" + formatOffset(from),
+		"TZOFFSETTO:" + formatOffset(offset),
+		"TZNAME:" + name,
+		"END:" + kind,
+	}
+}
+
+// isDaylight returns true if the offset at t is not the smallest offset of the year
+func isDaylight(loc *time.Location, t time.Time) bool {
+	_, winter := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, summer := time.Date(t.Year(), time.July, 1, 0, 0, 0, 0, loc).Zone()
+	_, offset := t.Zone()
+	return offset > winter || offset > summer
+}
+
+// formatOffset formats an offset in seconds as +HHMM
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset%3600/60)
+}
@@ -0,0 +1,60 @@
+package printers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/expressions"
+	"github.com/reclaro/cep/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func printICS(t *testing.T, loc *time.Location) string {
+	holder, err := expressions.NewDefaultSyntax("0 9 * * * /bin/backup")
+	require.Nil(t, err)
+	p, err := parsers.NewDefaultParser(holder)
+	require.Nil(t, err)
+	res, err := p.Results()
+	require.Nil(t, err)
+	runs := []time.Time{
+		time.Date(2021, 1, 1, 9, 0, 0, 0, loc),
+		time.Date(2021, 1, 2, 9, 0, 0, 0, loc),
+	}
+	var b bytes.Buffer
+	prt := &ICS{out: &b, duration: time.Minute, now: func() time.Time { return time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC) }}
+	prt.PrintRuns(res, "/bin/backup", runs)
+	return b.String()
+}
+
+func TestICSTimeZone(t *testing.T) {
+	rome, err := time.LoadLocation("Europe/Rome")
+	require.Nil(t, err)
+	out := printICS(t, rome)
+	assert.Contains(t, out, "TZID:Europe/Rome\r\n")
+	assert.Contains(t, out, "DTSTART;TZID=Europe/Rome:20210101T090000\r\n")
+}
+
+func TestICSWithoutTZID(t *testing.T) {
+	tcs := []struct {
+		name string
+		loc  *time.Location
+		utc  string
+	}{
+		{"utc", time.UTC, "20210101T090000Z"},
+		{"local", time.Local, time.Date(2021, 1, 1, 9, 0, 0, 0, time.Local).UTC().Format(icsUTCTime)},
+		{"fixed offset", time.FixedZone("UTC+2", 2*60*60), "20210101T070000Z"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			out := printICS(t, tc.loc)
+			assert.NotContains(t, out, "TZID")
+			assert.NotContains(t, out, "VTIMEZONE")
+			assert.Contains(t, out, "DTSTART:"+tc.utc+"\r\n")
+			assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+)
+
+// runFormat is the format of the times printed by the NextRuns printer, runSecondsFormat is used
+// when some runs are not at second 0
+const (
+	runFormat        = "Mon 2006-01-02 15:04 MST"
+	runSecondsFormat = "Mon 2006-01-02 15:04:05 MST"
+)
+
+// RunsPrinter defines the method to print a list of runs of a schedule, the command is the one
+// executed by the runs
+type RunsPrinter interface {
+	PrintRuns(s parsers.Schedule, command string, runs []time.Time)
+}
+
+// NextRuns prints the description of a schedule followed by its runs, one per line
+type NextRuns struct {
+	out io.Writer
+}
+
+// NewNextRuns returns a printer for the runs of a schedule that writes on the standard output
+func NewNextRuns() RunsPrinter {
+	return &NextRuns{out: os.Stdout}
+}
+
+// PrintRuns prints the description of the schedule and the runs
+func (p *NextRuns) PrintRuns(s parsers.Schedule, command string, runs []time.Time) {
+	fmt.Fprintf(p.out, "%-14s%s\n", "schedule", parsers.Describe(s))
+	if len(runs) == 0 {
+		fmt.Fprintf(p.out, "%-14s%s\n", "next", "never")
+	}
+	format := runFormat
+	for _, t := range runs {
+		if t.Second() != 0 {
+			format = runSecondsFormat
+		}
+	}
+	for _, t := range runs {
+		fmt.Fprintf(p.out, "%-14s%s\n", "next", t.Format(format))
+	}
+}
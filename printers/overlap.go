@@ -0,0 +1,36 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/reclaro/cep/analysis"
+)
+
+// OverlapReport prints the result of the overlap analysis, a line for every job and a line for every
+// conflict between jobs sharing a lock
+type OverlapReport struct {
+	out io.Writer
+}
+
+// NewOverlapReport returns a printer for the overlap analysis that writes on the standard output
+func NewOverlapReport() *OverlapReport {
+	return &OverlapReport{out: os.Stdout}
+}
+
+// Print prints the overlap report
+func (p *OverlapReport) Print(report *analysis.OverlapReport) {
+	for _, j := range report.Jobs {
+		status := "ok"
+		if j.Overlaps {
+			status = "OVERLAP"
+		}
+		fmt.Fprintf(p.out, "%-20.20s min gap %-12s duration %-12s %s\n", j.Job.Name, j.MinGap, j.Job.Duration, status)
+	}
+	for _, c := range report.Conflicts {
+		fmt.Fprintf(p.out, "lock %s: '%s' and '%s' run together %d times, first at %s\n",
+			c.Lock, c.First.Name, c.Second.Name, c.Count, c.At.Format(time.RFC3339))
+	}
+}
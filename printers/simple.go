@@ -15,16 +15,22 @@ const (
 	month      = "month"
 	dayOfWeek  = "day of week"
 	command    = "command"
+	second     = "second"
+	year       = "year"
+	every      = "every"
 )
 
 const (
 	table = `
-{{.Minutes}}
+{{if .Every}}{{.Every}}
+{{else}}{{if .Second}}{{.Second}}
+{{end}}{{.Minutes}}
 {{.Hours}}
 {{.DayMonth}}
 {{.Month}}
 {{.DayWeek}}
-{{.Command}}
+{{if .Year}}{{.Year}}
+{{end}}{{end}}{{.Command}}
 `
 )
 
@@ -39,6 +45,9 @@ type Simple struct {
 	Month    string
 	DayWeek  string
 	Command  string
+	Second   string
+	Every    string
+	Year     string
 }
 
 func NewSimple() Printer {
@@ -51,9 +60,18 @@ func (p *Simple) Print(exp *parsers.CronResults) {
 	p.Minutes = fmt.Sprintf("%-14s%s", p.trimCol(minutes), strings.Trim(fmt.Sprintf("%+v", exp.Minute), "[]"))
 	p.Hours = fmt.Sprintf("%-14s%s", p.trimCol(hour), strings.Trim(fmt.Sprintf("%+v", exp.Hour), "[]"))
 	p.Month = fmt.Sprintf("%-14s%s", p.trimCol(month), strings.Trim(fmt.Sprintf("%+v", exp.Month), "[]"))
-	p.DayMonth = fmt.Sprintf("%-14s%s", p.trimCol(dayOfMonth), strings.Trim(fmt.Sprintf("%+v", exp.DayMonth), "[]"))
-	p.DayWeek = fmt.Sprintf("%-14s%s", p.trimCol(dayOfWeek), strings.Trim(fmt.Sprintf("%+v", exp.DayWeek), "[]"))
+	p.DayMonth = fmt.Sprintf("%-14s%s", p.trimCol(dayOfMonth), p.days(exp.DayMonth, exp.DayMonthRules))
+	p.DayWeek = fmt.Sprintf("%-14s%s", p.trimCol(dayOfWeek), p.days(exp.DayWeek, exp.DayWeekRules))
 	p.Command = fmt.Sprintf("%-14s%s", p.trimCol(command), exp.Command)
+	if len(exp.Second) > 0 {
+		p.Second = fmt.Sprintf("%-14s%s", p.trimCol(second), strings.Trim(fmt.Sprintf("%+v", exp.Second), "[]"))
+	}
+	if len(exp.Year) > 0 {
+		p.Year = fmt.Sprintf("%-14s%s", p.trimCol(year), strings.Trim(fmt.Sprintf("%+v", exp.Year), "[]"))
+	}
+	if exp.Every > 0 {
+		p.Every = fmt.Sprintf("%-14s%s", p.trimCol(every), exp.Every)
+	}
 
 	err := t.Execute(os.Stdout, p)
 	if err != nil {
@@ -61,6 +79,18 @@ func (p *Simple) Print(exp *parsers.CronResults) {
 	}
 }
 
+// days prints the values of a day field followed by its rules, e.g. L or 5L
+func (p *Simple) days(values []int, rules []parsers.DayRule) string {
+	res := []string{}
+	if len(values) > 0 {
+		res = append(res, strings.Trim(fmt.Sprintf("%+v", values), "[]"))
+	}
+	for _, r := range rules {
+		res = append(res, r.String())
+	}
+	return strings.Join(res, " ")
+}
+
 func (p *Simple) trimCol(s string) string {
 	return fmt.Sprintf("%.14s", s)
 }
@@ -0,0 +1,40 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/reclaro/cep/analysis"
+)
+
+// StaggerReport prints the result of the stagger optimizer. The output is a valid crontab file:
+// the load summary and the list of the changes are printed as comments before the patched crontab
+type StaggerReport struct {
+	out io.Writer
+}
+
+// NewStaggerReport returns a printer for the stagger optimizer that writes on the standard output
+func NewStaggerReport() *StaggerReport {
+	return &StaggerReport{out: os.Stdout}
+}
+
+// Print prints the before/after load summary, the proposed changes and the patched crontab
+func (p *StaggerReport) Print(plan *analysis.Plan) {
+	fmt.Fprintf(p.out, "# before: %s\n", p.summary(plan.Before))
+	fmt.Fprintf(p.out, "# after: %s\n", p.summary(plan.After))
+	for _, c := range plan.Changes {
+		fmt.Fprintf(p.out, "# line %d: '%s' -> '%s' (%+d min)\n", c.Line, strings.Join(c.Before, " "), strings.Join(c.After, " "), c.Shift)
+	}
+	fmt.Fprint(p.out, plan.Patched.String())
+}
+
+func (p *StaggerReport) summary(s analysis.Summary) string {
+	peaks := []string{}
+	for _, m := range s.PeakMinutes {
+		peaks = append(peaks, analysis.FormatMinute(m))
+	}
+	return fmt.Sprintf("peak %d jobs at %s, %d busy minutes, %.2f jobs per busy minute",
+		s.Peak, strings.Join(peaks, ","), s.BusyMinutes, s.Average)
+}
@@ -0,0 +1,37 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/reclaro/cep/analysis"
+)
+
+// StatsReport prints the statistics of a cron expression, one value per line
+type StatsReport struct {
+	out io.Writer
+}
+
+// NewStatsReport returns a printer for the statistics that writes on the standard output
+func NewStatsReport() *StatsReport {
+	return &StatsReport{out: os.Stdout}
+}
+
+// Print prints the statistics
+func (p *StatsReport) Print(s analysis.Stats) {
+	fmt.Fprintf(p.out, "%-16s%s\n", "min gap", s.MinGap)
+	fmt.Fprintf(p.out, "%-16s%s\n", "max gap", s.MaxGap)
+	fmt.Fprintf(p.out, "%-16s%s\n", "average gap", s.AverageGap)
+	fmt.Fprintf(p.out, "%-16s%d\n", "runs per day", s.RunsPerDay)
+	fmt.Fprintf(p.out, "%-16s%.2f\n", "runs per week", s.RunsPerWeek)
+	fmt.Fprintf(p.out, "%-16s%.2f\n", "runs per month", s.RunsPerMonth)
+	fmt.Fprintf(p.out, "%-16s%.2f\n", "runs per year", s.RunsPerYear)
+	idle := "no runs"
+	if !s.IdleStart.IsZero() {
+		idle = fmt.Sprintf("%s from %s to %s", s.IdleEnd.Sub(s.IdleStart),
+			s.IdleStart.Format(time.RFC3339), s.IdleEnd.Format(time.RFC3339))
+	}
+	fmt.Fprintf(p.out, "%-16s%s\n", fmt.Sprintf("idle in %d", s.Year), idle)
+}
@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/reclaro/cep/parsers"
+)
+
+// CatchUpPolicy tells which runs missed while the scheduler was not running are run when it starts
+type CatchUpPolicy int
+
+const (
+	// CatchUpNone does not run the missed runs, as cron does
+	CatchUpNone CatchUpPolicy = iota
+	// CatchUpOnce runs once if at least a run was missed, as anacron does
+	CatchUpOnce
+	// CatchUpAll runs every missed run up to the limit, only the last Limit ones or the last
+	// DefaultCatchUpLimit ones when the limit is not greater than 0
+	CatchUpAll
+	// CatchUpDeadline runs the last missed run if it was missed by no more than Deadline, as the starting
+	// deadline of the Kubernetes CronJobs
+	CatchUpDeadline
+)
+
+// DefaultCatchUpLimit is the number of missed runs run by CatchUpAll when the limit is not set, a job
+// that runs every minute misses thousands of runs in a few days of downtime
+const DefaultCatchUpLimit = 10
+
+// catchUpNames are the names of the catch-up policies as written in the crontab annotations
+var catchUpNames = []string{"none", "once", "all", "deadline"}
+
+func (p CatchUpPolicy) String() string {
+	if p < 0 || int(p) >= len(catchUpNames) {
+		return fmt.Sprintf("CatchUpPolicy(%d)", int(p))
+	}
+	return catchUpNames[p]
+}
+
+// ParseCatchUpPolicy returns the catch-up policy with the name, in any case
+func ParseCatchUpPolicy(name string) (CatchUpPolicy, error) {
+	for i, n := range catchUpNames {
+		if strings.EqualFold(n, name) {
+			return CatchUpPolicy(i), nil
+		}
+	}
+	return CatchUpNone, errors.New(fmt.Sprintf("Invalid catch-up policy '%s', expected one of %s", name, strings.Join(catchUpNames, ", ")))
+}
+
+// CatchUp is the catch-up policy of an entry with its parameters
+type CatchUp struct {
+	Policy   CatchUpPolicy
+	Limit    int
+	Deadline time.Duration
+}
+
+// Missed returns the runs of the schedule after last and not after now that the policy runs, sorted
+func (c CatchUp) Missed(schedule parsers.Schedule, last time.Time, now time.Time) []time.Time {
+	if c.Policy == CatchUpNone || last.IsZero() {
+		return nil
+	}
+	// only the last runs are kept, the schedule can have a lot of runs in a long downtime
+	keep := 1
+	if c.Policy == CatchUpAll {
+		keep = c.Limit
+		if keep <= 0 {
+			keep = DefaultCatchUpLimit
+		}
+	}
+	missed := []time.Time{}
+	for t := schedule.Next(last); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		missed = append(missed, t)
+		if len(missed) > keep {
+			missed = missed[1:]
+		}
+	}
+	if c.Policy == CatchUpDeadline && len(missed) > 0 && now.Sub(missed[0]) > c.Deadline {
+		return nil
+	}
+	return missed
+}
+
+// scheduledKey is the key of the time of the run in the context of the jobs
+type scheduledKey struct{}
+
+// ScheduledTime returns the time when the run of the job was due, it is before the start of the run for
+// the missed runs. It returns the zero time if the context is not the context of a job
+func ScheduledTime(ctx context.Context) time.Time {
+	t, _ := ctx.Value(scheduledKey{}).(time.Time)
+	return t
+}
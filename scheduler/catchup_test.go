@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCatchUpPolicy(t *testing.T) {
+	for _, p := range []CatchUpPolicy{CatchUpNone, CatchUpOnce, CatchUpAll, CatchUpDeadline} {
+		parsed, err := ParseCatchUpPolicy(p.String())
+		assert.Nil(t, err)
+		assert.Equal(t, p, parsed)
+	}
+	_, err := ParseCatchUpPolicy("some")
+	assert.NotNil(t, err)
+}
+
+func TestMissed(t *testing.T) {
+	hourly := interval(time.Hour)
+	last := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := last.Add(4*time.Hour + 30*time.Minute)
+	at := func(hours ...int) []time.Time {
+		res := []time.Time{}
+		for _, h := range hours {
+			res = append(res, last.Add(time.Duration(h)*time.Hour))
+		}
+		return res
+	}
+
+	tests := []struct {
+		name    string
+		catchUp CatchUp
+		last    time.Time
+		missed  []time.Time
+	}{
+		{"none", CatchUp{Policy: CatchUpNone}, last, nil},
+		{"never run", CatchUp{Policy: CatchUpAll}, time.Time{}, nil},
+		{"once", CatchUp{Policy: CatchUpOnce}, last, at(4)},
+		{"all", CatchUp{Policy: CatchUpAll}, last, at(1, 2, 3, 4)},
+		{"all with limit", CatchUp{Policy: CatchUpAll, Limit: 2}, last, at(3, 4)},
+		{"within deadline", CatchUp{Policy: CatchUpDeadline, Deadline: time.Hour}, last, at(4)},
+		{"past deadline", CatchUp{Policy: CatchUpDeadline, Deadline: 20 * time.Minute}, last, nil},
+		{"nothing missed", CatchUp{Policy: CatchUpAll}, now.Add(-time.Minute), []time.Time{}},
+		{"all with the default limit", CatchUp{Policy: CatchUpAll}, last.Add(-24 * time.Hour), at(-5, -4, -3, -2, -1, 0, 1, 2, 3, 4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.missed, tt.catchUp.Missed(hourly, tt.last, now))
+		})
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	start := time.Date(2021, 1, 1, 3, 30, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	s := New(time.UTC)
+	s.Clock = fake
+	runs := make(chan time.Time, 10)
+	id, err := s.AddExpression("backup", "robfig", "0 * * * *", func(ctx context.Context) error {
+		runs <- ScheduledTime(ctx)
+		return nil
+	})
+	require.Nil(t, err)
+	last := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Nil(t, s.SetCatchUp(id, CatchUp{Policy: CatchUpAll}, last))
+	require.Nil(t, s.Start(context.Background()))
+
+	for _, h := range []int{1, 2, 3} {
+		select {
+		case at := <-runs:
+			assert.Equal(t, last.Add(time.Duration(h)*time.Hour), at)
+		case <-time.After(time.Second):
+			t.Fatalf("missing the run at %d", h)
+		}
+	}
+	fake.BlockUntil(1)
+	fake.Advance(30 * time.Minute)
+	select {
+	case at := <-runs:
+		assert.Equal(t, start.Add(30*time.Minute), at)
+	case <-time.After(time.Second):
+		t.Fatal("missing the scheduled run")
+	}
+	require.Nil(t, s.Stop(context.Background()))
+	assert.Equal(t, start.Add(30*time.Minute), s.Entries()[0].Prev)
+}
+
+func TestScheduledTime(t *testing.T) {
+	assert.True(t, ScheduledTime(context.Background()).IsZero())
+}
@@ -0,0 +1,487 @@
+/*
+Package scheduler runs Go functions at the times of parsed cron expressions inside the process.
+
+A Scheduler holds a list of entries, each one made of a schedule and a job. Start runs the jobs at the
+next runs of their schedules until the context is cancelled or Stop is called, every run of a job is a
+new goroutine and it receives a context that is cancelled when the scheduler is cancelled.
+*/
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reclaro/cep/clock"
+	"github.com/reclaro/cep/expressions"
+	"github.com/reclaro/cep/parsers"
+)
+
+// Job is a function run by the scheduler, the context is cancelled when the scheduler is cancelled
+type Job func(ctx context.Context) error
+
+// Policy tells what to do when a run of a job is due while the previous runs of the job are still running,
+// as the concurrency policies of the Kubernetes CronJobs
+type Policy int
+
+const (
+	// Allow starts the new run and the runs go on in parallel
+	Allow Policy = iota
+	// Forbid skips the new run
+	Forbid
+	// Replace cancels the context of the running runs and it starts the new run when they finish
+	Replace
+)
+
+// policyNames are the names of the policies as written in the crontab annotations
+var policyNames = []string{"allow", "forbid", "replace"}
+
+func (p Policy) String() string {
+	if p < 0 || int(p) >= len(policyNames) {
+		return fmt.Sprintf("Policy(%d)", int(p))
+	}
+	return policyNames[p]
+}
+
+// ParsePolicy returns the policy with the name, in any case
+func ParsePolicy(name string) (Policy, error) {
+	for i, n := range policyNames {
+		if strings.EqualFold(n, name) {
+			return Policy(i), nil
+		}
+	}
+	return Allow, errors.New(fmt.Sprintf("Invalid concurrency policy '%s', expected one of %s", name, strings.Join(policyNames, ", ")))
+}
+
+// Entry is a job registered in the scheduler, Next is the time of the next run and Prev the time of the
+// last run, they are zero when there is not such a run. Running is the number of runs still running
+type Entry struct {
+	ID          int
+	Name        string
+	Schedule    parsers.Schedule
+	Job         Job
+	Concurrency Policy
+	CatchUp     CatchUp
+	Next        time.Time
+	Prev        time.Time
+	Running     int
+}
+
+// activeRun is a run of a job that did not finish yet
+type activeRun struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Scheduler runs the jobs of its entries at the runs of their schedules. ErrorHandler, when set, is
+// called with the errors returned by the jobs and with the panics of the jobs, SkipHandler with the runs
+// skipped by the Forbid policy. Clock is the clock of the system unless it is replaced, e.g. by a fake
+// clock in the tests, before Start
+type Scheduler struct {
+	ErrorHandler func(e Entry, err error)
+	SkipHandler  func(e Entry)
+	Clock        clock.Clock
+
+	mu       sync.Mutex
+	entries  []*Entry
+	nextID   int
+	location *time.Location
+	running  bool
+	wake     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	jobs     sync.WaitGroup
+	active   map[int][]*activeRun
+	// drained is closed when the jobs started before the last Stop finished
+	drained chan struct{}
+}
+
+// New returns a scheduler that computes the runs in the location, the local one if it is nil
+func New(location *time.Location) *Scheduler {
+	if location == nil {
+		location = time.Local
+	}
+	return &Scheduler{
+		Clock:    clock.New(),
+		location: location,
+		wake:     make(chan struct{}, 1),
+		active:   map[int][]*activeRun{},
+	}
+}
+
+// Add registers a job for a schedule and it returns the ID of the entry
+func (s *Scheduler) Add(name string, schedule parsers.Schedule, job Job) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	e := &Entry{ID: s.nextID, Name: name, Schedule: schedule, Job: job}
+	if s.running {
+		e.Next = schedule.Next(s.Clock.Now().In(s.location))
+	}
+	s.entries = append(s.entries, e)
+	s.notify()
+	return e.ID
+}
+
+// AddExpression registers a job for a cron expression in a dialect, e.g. vixie or quartz, and it returns
+// the ID of the entry
+func (s *Scheduler) AddExpression(name string, dialect string, expression string, job Job) (int, error) {
+	d, err := expressions.LookupDialect(dialect)
+	if err != nil {
+		return 0, err
+	}
+	holder, err := d.New(expression)
+	if err != nil {
+		return 0, err
+	}
+	p, err := parsers.NewDefaultParser(holder)
+	if err != nil {
+		return 0, err
+	}
+	res, err := p.Results()
+	if err != nil {
+		return 0, err
+	}
+	return s.Add(name, res, job), nil
+}
+
+// SetConcurrency sets the concurrency policy of an entry, the policy is Allow when it is not set
+func (s *Scheduler) SetConcurrency(id int, policy Policy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entry(id)
+	if e == nil {
+		return errors.New(fmt.Sprintf("Entry %d not found", id))
+	}
+	e.Concurrency = policy
+	return nil
+}
+
+// SetCatchUp sets the catch-up policy of an entry and the time of its last run before the scheduler was
+// started, the runs missed since then are run by Start according to the policy
+func (s *Scheduler) SetCatchUp(id int, c CatchUp, last time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.entry(id)
+	if e == nil {
+		return errors.New(fmt.Sprintf("Entry %d not found", id))
+	}
+	e.CatchUp = c
+	e.Prev = last
+	return nil
+}
+
+// entry returns the entry with the ID or nil, it must be called holding the lock
+func (s *Scheduler) entry(id int) *Entry {
+	for _, e := range s.entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+// Trigger starts a run of an entry now, out of its schedule, applying its concurrency policy. It returns
+// an error if the entry does not exist or the scheduler is not running
+func (s *Scheduler) Trigger(id int) error {
+	s.mu.Lock()
+	e := s.entry(id)
+	if e == nil {
+		s.mu.Unlock()
+		return errors.New(fmt.Sprintf("Entry %d not found", id))
+	}
+	if !s.running {
+		s.mu.Unlock()
+		return errors.New("The scheduler is not running")
+	}
+	r := s.start(s.ctx, e, s.Clock.Now().In(s.location))
+	skipped := *e
+	s.mu.Unlock()
+	if r == nil {
+		s.skip([]Entry{skipped})
+	}
+	return nil
+}
+
+// Remove removes an entry, the runs of its job that already started are not cancelled
+func (s *Scheduler) Remove(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.entries {
+		if e.ID == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			s.notify()
+			return
+		}
+	}
+}
+
+// Entries returns a copy of the entries sorted by their next run, the entries that never run are the last ones
+func (s *Scheduler) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := []Entry{}
+	for _, e := range s.entries {
+		entry := *e
+		entry.Running = len(s.active[e.ID])
+		res = append(res, entry)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Next.IsZero() || res[j].Next.IsZero() {
+			return !res[i].Next.IsZero()
+		}
+		return res[i].Next.Before(res[j].Next)
+	})
+	return res
+}
+
+// Start starts running the jobs in a new goroutine, the scheduler stops when the context is cancelled
+// and the context of the running jobs is cancelled too. The runs missed since the last run of the entries
+// with a catch-up policy are run one after the other
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return errors.New("The scheduler is already running")
+	}
+	if s.drained != nil {
+		select {
+		case <-s.drained:
+		default:
+			return errors.New("The scheduler cannot start until the jobs of its previous run finish")
+		}
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.ctx = ctx
+	s.running = true
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	now := s.Clock.Now().In(s.location)
+	for _, e := range s.entries {
+		e.Next = e.Schedule.Next(now)
+		if missed := e.CatchUp.Missed(e.Schedule, e.Prev.In(s.location), now); len(missed) > 0 {
+			s.jobs.Add(1)
+			go s.catchUp(ctx, e.ID, missed)
+		}
+	}
+	go s.loop(ctx, s.stop, s.done)
+	return nil
+}
+
+/*
+Stop stops running new jobs and it waits for the running jobs to finish, it must be called also when the
+context of Start is cancelled to wait for the jobs.
+If the context is done before the jobs finish, the context of the jobs is cancelled and Stop returns the
+error of the context without waiting any longer, the scheduler cannot be started again until they finish.
+*/
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = false
+	close(s.stop)
+	done, cancel := s.done, s.cancel
+	// a Start right after this Stop must wait for the jobs
+	finished := make(chan struct{})
+	s.drained = finished
+	s.mu.Unlock()
+	<-done
+
+	go func() {
+		s.jobs.Wait()
+		close(finished)
+	}()
+	defer cancel()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop waits for the next run of the entries and it starts their jobs, it closes done when it returns
+func (s *Scheduler) loop(ctx context.Context, stop chan struct{}, done chan struct{}) {
+	defer close(done)
+	for {
+		var timer <-chan time.Time
+		var t clock.Timer
+		if next := s.next(); !next.IsZero() {
+			t = s.Clock.NewTimer(next.Sub(s.Clock.Now()))
+			timer = t.C()
+		}
+		stopped := false
+		select {
+		case now := <-timer:
+			s.runDue(ctx, now.In(s.location))
+		case <-s.wake:
+		case <-stop:
+			stopped = true
+		case <-ctx.Done():
+			stopped = true
+		}
+		if t != nil {
+			t.Stop()
+		}
+		if stopped {
+			return
+		}
+	}
+}
+
+// next returns the first run of all the entries or the zero time if no entry runs
+func (s *Scheduler) next() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var next time.Time
+	for _, e := range s.entries {
+		if !e.Next.IsZero() && (next.IsZero() || e.Next.Before(next)) {
+			next = e.Next
+		}
+	}
+	return next
+}
+
+// runDue starts the jobs of the entries whose next run is not after now, applying their concurrency policy
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	skipped := []Entry{}
+	s.mu.Lock()
+	for _, e := range s.entries {
+		if e.Next.IsZero() || e.Next.After(now) {
+			continue
+		}
+		scheduled := e.Next
+		e.Next = e.Schedule.Next(now)
+		if s.start(ctx, e, scheduled) == nil {
+			skipped = append(skipped, *e)
+		}
+	}
+	s.mu.Unlock()
+	s.skip(skipped)
+}
+
+// catchUp runs the missed runs of an entry one after the other, it stops when the scheduler is stopped or
+// the entry is removed
+func (s *Scheduler) catchUp(ctx context.Context, id int, missed []time.Time) {
+	defer s.jobs.Done()
+	s.mu.Lock()
+	stop := s.stop
+	s.mu.Unlock()
+	for _, t := range missed {
+		s.mu.Lock()
+		e := s.entry(id)
+		if e == nil || !s.running {
+			s.mu.Unlock()
+			return
+		}
+		r := s.start(ctx, e, t)
+		skipped := *e
+		s.mu.Unlock()
+		if r == nil {
+			s.skip([]Entry{skipped})
+			continue
+		}
+		select {
+		case <-r.done:
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// start starts a run of an entry due at the time scheduled applying the concurrency policy, it returns nil
+// if the run is skipped. It must be called holding the lock
+func (s *Scheduler) start(ctx context.Context, e *Entry, scheduled time.Time) *activeRun {
+	if scheduled.After(e.Prev) {
+		e.Prev = scheduled
+	}
+	previous := s.active[e.ID]
+	if len(previous) > 0 && e.Concurrency == Forbid {
+		return nil
+	}
+	if e.Concurrency == Replace {
+		for _, r := range previous {
+			r.cancel()
+		}
+	} else {
+		previous = nil
+	}
+	runCtx, cancel := context.WithCancel(context.WithValue(ctx, scheduledKey{}, scheduled))
+	r := &activeRun{cancel: cancel, done: make(chan struct{})}
+	s.active[e.ID] = append(s.active[e.ID], r)
+	s.jobs.Add(1)
+	go s.run(runCtx, *e, r, previous)
+	return r
+}
+
+// skip reports the skipped runs to the skip handler
+func (s *Scheduler) skip(skipped []Entry) {
+	if s.SkipHandler == nil {
+		return
+	}
+	for _, e := range skipped {
+		s.SkipHandler(e)
+	}
+}
+
+// run waits for the replaced runs to finish, then it runs a job, unless its context is already cancelled,
+// and it reports its error or its panic to the error handler
+func (s *Scheduler) run(ctx context.Context, e Entry, r *activeRun, replaced []*activeRun) {
+	defer s.jobs.Done()
+	defer s.finish(e.ID, r)
+	for _, p := range replaced {
+		<-p.done
+	}
+	// the run can be replaced in turn while it waits
+	if ctx.Err() != nil {
+		return
+	}
+	var err error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = errors.New(fmt.Sprintf("panic: %v", r))
+			}
+		}()
+		err = e.Job(ctx)
+	}()
+	if err != nil && s.ErrorHandler != nil {
+		s.ErrorHandler(e, err)
+	}
+}
+
+// finish removes a run from the active runs of an entry
+func (s *Scheduler) finish(id int, r *activeRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r.cancel()
+	close(r.done)
+	runs := s.active[id]
+	for i, a := range runs {
+		if a == r {
+			s.active[id] = append(runs[:i:i], runs[i+1:]...)
+			break
+		}
+	}
+	if len(s.active[id]) == 0 {
+		delete(s.active, id)
+	}
+}
+
+// notify wakes up the loop to compute again the next run, it must be called holding the lock
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
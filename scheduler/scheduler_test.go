@@ -0,0 +1,422 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/reclaro/cep/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// interval is a schedule that runs every d from the start time
+type interval time.Duration
+
+func (i interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(i))
+}
+
+func (i interval) Matches(t time.Time) bool {
+	return false
+}
+
+// never is a schedule that never runs
+type never struct{}
+
+func (never) Next(t time.Time) time.Time {
+	return time.Time{}
+}
+
+func (never) Matches(t time.Time) bool {
+	return false
+}
+
+func TestRun(t *testing.T) {
+	s := New(time.UTC)
+	var runs int32
+	s.Add("count", interval(10*time.Millisecond), func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	require.Nil(t, s.Start(context.Background()))
+	assert.NotNil(t, s.Start(context.Background()))
+	time.Sleep(55 * time.Millisecond)
+	require.Nil(t, s.Stop(context.Background()))
+	count := atomic.LoadInt32(&runs)
+	assert.True(t, count >= 3 && count <= 6, "unexpected number of runs %d", count)
+
+	// no runs after Stop
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, count, atomic.LoadInt32(&runs))
+}
+
+func TestAddWhileRunning(t *testing.T) {
+	s := New(nil)
+	require.Nil(t, s.Start(context.Background()))
+	ran := make(chan struct{}, 10)
+	id := s.Add("late", interval(10*time.Millisecond), func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	})
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("the job added while running did not run")
+	}
+	s.Remove(id)
+	assert.Empty(t, s.Entries())
+	require.Nil(t, s.Stop(context.Background()))
+}
+
+func TestStopWaitsForJobs(t *testing.T) {
+	s := New(time.UTC)
+	started := make(chan struct{})
+	var finished int32
+	var once sync.Once
+	s.Add("slow", interval(10*time.Millisecond), func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		time.Sleep(50 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+		return nil
+	})
+	require.Nil(t, s.Start(context.Background()))
+	<-started
+	require.Nil(t, s.Stop(context.Background()))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+}
+
+func TestStopTimeoutCancelsJobs(t *testing.T) {
+	s := New(time.UTC)
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	var once sync.Once
+	s.Add("stuck", interval(10*time.Millisecond), func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		<-ctx.Done()
+		cancelled <- struct{}{}
+		return ctx.Err()
+	})
+	require.Nil(t, s.Start(context.Background()))
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, s.Stop(ctx))
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("the context of the job was not cancelled")
+	}
+}
+
+func TestRestartAfterStopTimeout(t *testing.T) {
+	s := New(time.UTC)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	s.Add("stubborn", interval(10*time.Millisecond), func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		// the job ignores the cancellation of its context
+		<-release
+		return nil
+	})
+	require.Nil(t, s.Start(context.Background()))
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, s.Stop(ctx))
+	assert.EqualError(t, s.Start(context.Background()), "The scheduler cannot start until the jobs of its previous run finish")
+
+	close(release)
+	var err error
+	for i := 0; i < 100; i++ {
+		if err = s.Start(context.Background()); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	require.Nil(t, err)
+	require.Nil(t, s.Stop(context.Background()))
+}
+
+func TestStartDuringStop(t *testing.T) {
+	s := New(time.UTC)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	s.Add("slow", interval(10*time.Millisecond), func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		<-release
+		return nil
+	})
+	require.Nil(t, s.Start(context.Background()))
+	<-started
+	stopped := make(chan error, 1)
+	go func() { stopped <- s.Stop(context.Background()) }()
+	// the scheduler is not running as soon as Stop is called, but its jobs are
+	running := func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.running
+	}
+	for running() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.EqualError(t, s.Start(context.Background()), "The scheduler cannot start until the jobs of its previous run finish")
+	close(release)
+	assert.Nil(t, <-stopped)
+}
+
+func TestContextCancellation(t *testing.T) {
+	s := New(time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	s.Add("wait", interval(10*time.Millisecond), func(ctx context.Context) error {
+		<-ctx.Done()
+		select {
+		case done <- ctx.Err():
+		default:
+		}
+		return nil
+	})
+	require.Nil(t, s.Start(ctx))
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.Equal(t, context.Canceled, err)
+	case <-time.After(time.Second):
+		t.Fatal("the context of the job was not cancelled")
+	}
+	require.Nil(t, s.Stop(context.Background()))
+}
+
+func TestErrorHandler(t *testing.T) {
+	s := New(time.UTC)
+	errs := make(chan error, 10)
+	s.ErrorHandler = func(e Entry, err error) {
+		errs <- err
+	}
+	s.Add("fail", interval(10*time.Millisecond), func(ctx context.Context) error {
+		return errors.New("failed")
+	})
+	s.Add("panic", interval(15*time.Millisecond), func(ctx context.Context) error {
+		panic("boom")
+	})
+	require.Nil(t, s.Start(context.Background()))
+	seen := map[string]bool{}
+	for len(seen) < 2 {
+		select {
+		case err := <-errs:
+			seen[err.Error()] = true
+		case <-time.After(time.Second):
+			t.Fatalf("missing errors, got %v", seen)
+		}
+	}
+	require.Nil(t, s.Stop(context.Background()))
+	assert.True(t, seen["failed"])
+	assert.True(t, seen["panic: boom"])
+}
+
+func TestEntries(t *testing.T) {
+	s := New(time.UTC)
+	s.Add("never", never{}, func(ctx context.Context) error { return nil })
+	s.Add("hourly", interval(time.Hour), func(ctx context.Context) error { return nil })
+	s.Add("minutely", interval(time.Minute), func(ctx context.Context) error { return nil })
+	require.Nil(t, s.Start(context.Background()))
+	defer s.Stop(context.Background())
+	names := []string{}
+	for _, e := range s.Entries() {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{"minutely", "hourly", "never"}, names)
+}
+
+func TestAddExpression(t *testing.T) {
+	s := New(time.UTC)
+	_, err := s.AddExpression("report", "quartz", "0 15 10 ? * 6L", func(ctx context.Context) error { return nil })
+	assert.Nil(t, err)
+	_, err = s.AddExpression("report", "quartz", "0 15 10 * * *", func(ctx context.Context) error { return nil })
+	assert.NotNil(t, err)
+	_, err = s.AddExpression("report", "cobol", "0 15 10 * * *", func(ctx context.Context) error { return nil })
+	assert.NotNil(t, err)
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	s := New(time.UTC)
+	s.Clock = fake
+	runs := make(chan time.Time, 10)
+	_, err := s.AddExpression("five", "quartz", "0 */5 * * * ?", func(ctx context.Context) error {
+		runs <- fake.Now()
+		return nil
+	})
+	require.Nil(t, err)
+	require.Nil(t, s.Start(context.Background()))
+	defer s.Stop(context.Background())
+
+	for i := 1; i <= 3; i++ {
+		fake.BlockUntil(1)
+		next := start.Add(time.Duration(i*5) * time.Minute)
+		assert.Equal(t, []time.Time{next}, fake.Timers())
+		fake.Set(next)
+		select {
+		case at := <-runs:
+			assert.Equal(t, next, at)
+		case <-time.After(time.Second):
+			t.Fatalf("the job did not run at %s", next)
+		}
+	}
+	entries := s.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, start.Add(15*time.Minute), entries[0].Prev)
+	assert.Equal(t, start.Add(20*time.Minute), entries[0].Next)
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, p := range []Policy{Allow, Forbid, Replace} {
+		parsed, err := ParsePolicy(p.String())
+		assert.Nil(t, err)
+		assert.Equal(t, p, parsed)
+	}
+	p, err := ParsePolicy("Forbid")
+	assert.Nil(t, err)
+	assert.Equal(t, Forbid, p)
+	_, err = ParsePolicy("queue")
+	assert.NotNil(t, err)
+}
+
+// blockingJob is a job whose runs block until they are released or cancelled
+type blockingJob struct {
+	started   chan int
+	release   chan struct{}
+	cancelled chan int
+	runs      int32
+}
+
+func newBlockingJob() *blockingJob {
+	return &blockingJob{started: make(chan int, 10), release: make(chan struct{}), cancelled: make(chan int, 10)}
+}
+
+func (b *blockingJob) run(ctx context.Context) error {
+	n := int(atomic.AddInt32(&b.runs, 1))
+	b.started <- n
+	select {
+	case <-b.release:
+		return nil
+	case <-ctx.Done():
+		b.cancelled <- n
+		return ctx.Err()
+	}
+}
+
+// receive returns the value received from the channel or it fails the test after a second
+func receive(t *testing.T, c chan int, what string) int {
+	select {
+	case n := <-c:
+		return n
+	case <-time.After(time.Second):
+		t.Fatalf("timeout waiting for %s", what)
+	}
+	return 0
+}
+
+// startPolicy starts a scheduler with a fake clock and a job that runs every minute with the policy
+func startPolicy(t *testing.T, policy Policy, job Job) (*Scheduler, *clock.Fake) {
+	fake := clock.NewFake(time.Date(2021, 1, 1, 0, 0, 30, 0, time.UTC))
+	s := New(time.UTC)
+	s.Clock = fake
+	id, err := s.AddExpression("minutely", "robfig", "* * * * *", job)
+	require.Nil(t, err)
+	require.Nil(t, s.SetConcurrency(id, policy))
+	require.Nil(t, s.Start(context.Background()))
+	return s, fake
+}
+
+// tick advances the clock to the next minute and it waits for the scheduler to handle it
+func tick(fake *clock.Fake) {
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	fake.BlockUntil(1)
+}
+
+func TestPolicyAllow(t *testing.T) {
+	b := newBlockingJob()
+	s, fake := startPolicy(t, Allow, b.run)
+	tick(fake)
+	assert.Equal(t, 1, receive(t, b.started, "the first run"))
+	tick(fake)
+	assert.Equal(t, 2, receive(t, b.started, "the second run"))
+	assert.Equal(t, 2, s.Entries()[0].Running)
+	close(b.release)
+	require.Nil(t, s.Stop(context.Background()))
+	assert.Equal(t, 0, s.Entries()[0].Running)
+}
+
+func TestPolicyForbid(t *testing.T) {
+	b := newBlockingJob()
+	s, fake := startPolicy(t, Forbid, b.run)
+	skipped := make(chan int, 10)
+	s.SkipHandler = func(e Entry) {
+		skipped <- e.ID
+	}
+	tick(fake)
+	assert.Equal(t, 1, receive(t, b.started, "the first run"))
+	tick(fake)
+	receive(t, skipped, "the skipped run")
+	assert.Equal(t, 1, s.Entries()[0].Running)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&b.runs))
+
+	b.release <- struct{}{}
+	for s.Entries()[0].Running > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	tick(fake)
+	assert.Equal(t, 2, receive(t, b.started, "the run after the first finished"))
+	close(b.release)
+	require.Nil(t, s.Stop(context.Background()))
+}
+
+func TestPolicyReplace(t *testing.T) {
+	b := newBlockingJob()
+	s, fake := startPolicy(t, Replace, b.run)
+	errs := make(chan error, 10)
+	s.ErrorHandler = func(e Entry, err error) {
+		errs <- err
+	}
+	tick(fake)
+	assert.Equal(t, 1, receive(t, b.started, "the first run"))
+	tick(fake)
+	assert.Equal(t, 1, receive(t, b.cancelled, "the cancellation of the first run"))
+	assert.Equal(t, 2, receive(t, b.started, "the second run"))
+	assert.Equal(t, context.Canceled, <-errs)
+	assert.Equal(t, 1, s.Entries()[0].Running)
+	close(b.release)
+	require.Nil(t, s.Stop(context.Background()))
+}
+
+func TestTrigger(t *testing.T) {
+	s := New(time.UTC)
+	b := newBlockingJob()
+	id := s.Add("manual", never{}, b.run)
+	assert.NotNil(t, s.Trigger(id))
+	s.SetConcurrency(id, Forbid)
+	skipped := make(chan int, 10)
+	s.SkipHandler = func(e Entry) {
+		skipped <- e.ID
+	}
+	require.Nil(t, s.Start(context.Background()))
+	assert.NotNil(t, s.Trigger(id+1))
+
+	require.Nil(t, s.Trigger(id))
+	assert.Equal(t, 1, receive(t, b.started, "the triggered run"))
+	require.Nil(t, s.Trigger(id))
+	assert.Equal(t, id, receive(t, skipped, "the skipped run"))
+	close(b.release)
+	require.Nil(t, s.Stop(context.Background()))
+	assert.False(t, s.Entries()[0].Prev.IsZero())
+}
@@ -0,0 +1,104 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File is a store that keeps the state in a JSON file. Every update writes a temporary file in the same
+// directory, syncs it to the disk and renames it over the file, so that the file always holds either the
+// previous or the new state
+type File struct {
+	mu   sync.Mutex
+	path string
+	jobs map[string]Job
+}
+
+// NewFile returns a store that keeps the state in the file at path, the state is read from the file when
+// it exists and it is empty otherwise
+func NewFile(path string) (*File, error) {
+	f := &File{path: path, jobs: map[string]Job{}}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &f.jobs); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid state file '%s': %s", path, err.Error()))
+	}
+	return f, nil
+}
+
+// Get returns the state of a job, the zero state when the job is unknown
+func (f *File) Get(name string) (Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return copyJob(f.jobs[name]), nil
+}
+
+// Update changes the state of a job with the function and it writes the file, the state is not changed if
+// the function returns an error or the file cannot be written
+func (f *File) Update(name string, fn func(j *Job) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	j := copyJob(f.jobs[name])
+	if err := fn(&j); err != nil {
+		return err
+	}
+	jobs := copyJobs(f.jobs)
+	jobs[name] = j
+	if err := f.write(jobs); err != nil {
+		return err
+	}
+	f.jobs = jobs
+	return nil
+}
+
+// Jobs returns a copy of the state of all the jobs
+func (f *File) Jobs() (map[string]Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return copyJobs(f.jobs), nil
+}
+
+// write replaces the file with the state of the jobs
+func (f *File) write(jobs map[string]Job) error {
+	b, err := json.MarshalIndent(jobs, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(f.path)
+	tmp, err := ioutil.TempFile(dir, "."+filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	// the temporary file is removed if the rename does not happen
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(b, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return err
+	}
+	// the rename is durable only when the directory is synced too
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
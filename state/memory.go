@@ -0,0 +1,57 @@
+package state
+
+import "sync"
+
+// Memory is a store that keeps the state in memory, the state is lost when the program ends
+type Memory struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+// NewMemory returns an empty memory store
+func NewMemory() *Memory {
+	return &Memory{jobs: map[string]Job{}}
+}
+
+// Get returns the state of a job, the zero state when the job is unknown
+func (m *Memory) Get(name string) (Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return copyJob(m.jobs[name]), nil
+}
+
+// Update changes the state of a job with the function, the state is not changed if it returns an error
+func (m *Memory) Update(name string, f func(j *Job) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j := copyJob(m.jobs[name])
+	if err := f(&j); err != nil {
+		return err
+	}
+	m.jobs[name] = j
+	return nil
+}
+
+// Jobs returns a copy of the state of all the jobs
+func (m *Memory) Jobs() (map[string]Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return copyJobs(m.jobs), nil
+}
+
+// copyJobs returns a copy of the map of the jobs, the histories are copied too
+func copyJobs(jobs map[string]Job) map[string]Job {
+	res := make(map[string]Job, len(jobs))
+	for name, j := range jobs {
+		res[name] = copyJob(j)
+	}
+	return res
+}
+
+// copyJob returns a copy of the job that does not share the history
+func copyJob(j Job) Job {
+	if j.History != nil {
+		j.History = append([]Attempt{}, j.History...)
+	}
+	return j
+}
@@ -0,0 +1,60 @@
+/*
+Package state keeps the state of the jobs run by the daemon between its restarts, e.g. the time of the last
+run used to catch up the missed runs.
+Store is the interface of the storage, Memory keeps the state in memory and File in a JSON file that is
+replaced atomically at every change, so that a crash in the middle of a write leaves the previous state.
+*/
+package state
+
+import "time"
+
+// HistoryLimit is the number of attempts kept in the history of a job
+const HistoryLimit = 20
+
+// Job is the state of a job. Scheduled is the time when the last run was due, it is the time used to find
+// the missed runs, the other fields describe the last attempt that finished and History the last attempts,
+// the oldest first
+type Job struct {
+	Scheduled time.Time     `json:"scheduled"`
+	Start     time.Time     `json:"start"`
+	End       time.Time     `json:"end"`
+	ExitCode  int           `json:"exit_code"`
+	Duration  time.Duration `json:"duration"`
+	History   []Attempt     `json:"history,omitempty"`
+}
+
+// Attempt is an execution of a run of a job, a run has more attempts when it is retried after a failure
+type Attempt struct {
+	Scheduled time.Time     `json:"scheduled"`
+	Attempt   int           `json:"attempt"`
+	Start     time.Time     `json:"start"`
+	End       time.Time     `json:"end"`
+	ExitCode  int           `json:"exit_code"`
+	Duration  time.Duration `json:"duration"`
+	TimedOut  bool          `json:"timed_out,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Record sets the last attempt of the job and it adds it to the history, only the last HistoryLimit
+// attempts are kept
+func (j *Job) Record(a Attempt) {
+	j.Start = a.Start
+	j.End = a.End
+	j.ExitCode = a.ExitCode
+	j.Duration = a.Duration
+	j.History = append(j.History, a)
+	if len(j.History) > HistoryLimit {
+		j.History = append([]Attempt{}, j.History[len(j.History)-HistoryLimit:]...)
+	}
+}
+
+// Store reads and updates the state of the jobs by their names, the implementations are safe to use from
+// more goroutines
+type Store interface {
+	// Get returns the state of a job, the zero state when the job is unknown
+	Get(name string) (Job, error)
+	// Update changes the state of a job with the function, the state is not saved if it returns an error
+	Update(name string, f func(j *Job) error) error
+	// Jobs returns the state of all the jobs
+	Jobs() (map[string]Job, error)
+}
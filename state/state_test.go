@@ -0,0 +1,143 @@
+package state
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2021, 1, 1, 3, 0, 0, 0, time.UTC)
+
+// tempDir creates a temporary directory and it returns the function that removes it
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "cep")
+	require.Nil(t, err)
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestStores(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	file, err := NewFile(filepath.Join(dir, "state.json"))
+	require.Nil(t, err)
+
+	for name, s := range map[string]Store{"memory": NewMemory(), "file": file} {
+		t.Run(name, func(t *testing.T) {
+			j, err := s.Get("backup")
+			assert.Nil(t, err)
+			assert.Equal(t, Job{}, j)
+
+			err = s.Update("backup", func(j *Job) error {
+				j.Scheduled = start
+				j.Start = start.Add(time.Second)
+				return nil
+			})
+			require.Nil(t, err)
+			err = s.Update("backup", func(j *Job) error {
+				j.End = j.Start.Add(time.Minute)
+				j.Duration = time.Minute
+				j.ExitCode = 2
+				return nil
+			})
+			require.Nil(t, err)
+			expected := Job{Scheduled: start, Start: start.Add(time.Second), End: start.Add(time.Minute + time.Second), ExitCode: 2, Duration: time.Minute}
+			j, _ = s.Get("backup")
+			assert.Equal(t, expected, j)
+
+			err = s.Update("backup", func(j *Job) error {
+				j.ExitCode = 0
+				return errors.New("failed")
+			})
+			assert.EqualError(t, err, "failed")
+			j, _ = s.Get("backup")
+			assert.Equal(t, 2, j.ExitCode)
+
+			jobs, err := s.Jobs()
+			assert.Nil(t, err)
+			assert.Equal(t, map[string]Job{"backup": expected}, jobs)
+			jobs["other"] = Job{}
+			jobs, _ = s.Jobs()
+			assert.Len(t, jobs, 1)
+		})
+	}
+}
+
+func TestFileReload(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	path := filepath.Join(dir, "state.json")
+	f, err := NewFile(path)
+	require.Nil(t, err)
+	require.Nil(t, f.Update("backup", func(j *Job) error {
+		j.Scheduled = start
+		j.Duration = 90 * time.Second
+		return nil
+	}))
+
+	reloaded, err := NewFile(path)
+	require.Nil(t, err)
+	j, _ := reloaded.Get("backup")
+	assert.True(t, start.Equal(j.Scheduled))
+	assert.Equal(t, 90*time.Second, j.Duration)
+
+	// only the state file is left in the directory
+	files, err := ioutil.ReadDir(dir)
+	require.Nil(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "state.json", files[0].Name())
+}
+
+func TestFileInvalid(t *testing.T) {
+	dir, remove := tempDir(t)
+	defer remove()
+	path := filepath.Join(dir, "state.json")
+	require.Nil(t, ioutil.WriteFile(path, []byte(`{"backup": `), 0644))
+	_, err := NewFile(path)
+	assert.NotNil(t, err)
+}
+
+func TestFileWriteError(t *testing.T) {
+	dir, remove := tempDir(t)
+	path := filepath.Join(dir, "state.json")
+	f, err := NewFile(path)
+	require.Nil(t, err)
+	remove()
+	err = f.Update("backup", func(j *Job) error {
+		j.ExitCode = 1
+		return nil
+	})
+	assert.NotNil(t, err)
+	j, _ := f.Get("backup")
+	assert.Equal(t, Job{}, j)
+}
+
+func TestRecord(t *testing.T) {
+	j := Job{Scheduled: start}
+	for i := 1; i <= HistoryLimit+5; i++ {
+		j.Record(Attempt{Scheduled: start, Attempt: i, Start: start, End: start.Add(time.Second), ExitCode: i, Duration: time.Second})
+	}
+	assert.Equal(t, HistoryLimit+5, j.ExitCode)
+	assert.Equal(t, start.Add(time.Second), j.End)
+	assert.Equal(t, time.Second, j.Duration)
+	require.Len(t, j.History, HistoryLimit)
+	assert.Equal(t, 6, j.History[0].Attempt)
+	assert.Equal(t, HistoryLimit+5, j.History[HistoryLimit-1].Attempt)
+}
+
+func TestHistoryNotShared(t *testing.T) {
+	s := NewMemory()
+	require.Nil(t, s.Update("backup", func(j *Job) error {
+		j.Record(Attempt{Attempt: 1})
+		return nil
+	}))
+	j, _ := s.Get("backup")
+	j.History[0].Attempt = 5
+	j, _ = s.Get("backup")
+	assert.Equal(t, 1, j.History[0].Attempt)
+}
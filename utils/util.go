@@ -1,8 +1,11 @@
 package utils
 
 import (
+	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // RangeValues receives an int of two values and return all the values between interval[0] and interval[1] included
@@ -45,3 +48,76 @@ func SortedUniqueInts(input []int) []int {
 	sort.Ints(input)
 	return input
 }
+
+// CompactValues receives a sorted array of int without duplicates and the allowed values of the field
+// (min and max value) and returns the shortest cron field that expands to the same values.
+// e.g. [0 15 30 45] with allowed values [0 59] becomes "*/15" and [1 2 3 7] becomes "1-3,7"
+func CompactValues(input []int, allowedValues []int) string {
+	if len(input) == 0 {
+		return ""
+	}
+	if len(input) == allowedValues[1]-allowedValues[0]+1 {
+		return "*"
+	}
+	// check if the values are a step expression that runs until the end of the allowed values
+	if len(input) > 2 {
+		step := input[1] - input[0]
+		isStep := step > 1
+		for i := 2; i < len(input) && isStep; i++ {
+			isStep = input[i]-input[i-1] == step
+		}
+		if isStep && input[len(input)-1]+step > allowedValues[1] {
+			if input[0] == allowedValues[0] {
+				return fmt.Sprintf("*/%d", step)
+			}
+			if input[0]-step < allowedValues[0] {
+				return fmt.Sprintf("%d/%d", input[0], step)
+			}
+		}
+	}
+	// otherwise we build a list of single values and intervals
+	parts := []string{}
+	for i := 0; i < len(input); {
+		j := i
+		for j+1 < len(input) && input[j+1] == input[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, fmt.Sprintf("%d-%d", input[i], input[j]))
+		case j-i == 1:
+			parts = append(parts, strconv.Itoa(input[i]), strconv.Itoa(input[j]))
+		default:
+			parts = append(parts, strconv.Itoa(input[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+// Transitions returns the instants from start to end when the offset of the location of start changes,
+// e.g. the start and the end of the daylight saving time. The precision is one minute
+func Transitions(start time.Time, end time.Time) []time.Time {
+	res := []time.Time{}
+	start = start.Truncate(time.Minute)
+	for day := start; day.Before(end); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		_, before := day.Zone()
+		_, after := next.Zone()
+		if before == after {
+			continue
+		}
+		// binary search of the first minute with the new offset
+		lo, hi := day, next
+		for hi.Sub(lo) > time.Minute {
+			mid := lo.Add((hi.Sub(lo) / 2).Truncate(time.Minute))
+			if _, o := mid.Zone(); o == before {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		res = append(res, hi)
+	}
+	return res
+}
@@ -2,7 +2,9 @@ package utils
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestStringToNumber(t *testing.T) {
@@ -48,3 +50,41 @@ func TestSortedUniqueInts(t *testing.T) {
 	actual := SortedUniqueInts(input)
 	assert.Equal(t, expected, actual)
 }
+
+func TestCompactValues(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    []int
+		expected string
+	}{
+		{"all values", RangeValues([]int{0, 59}), "*"},
+		{"step from start", []int{0, 15, 30, 45}, "*/15"},
+		{"step with offset", []int{5, 20, 35, 50}, "5/15"},
+		{"single value", []int{7}, "7"},
+		{"interval and value", []int{1, 2, 3, 7}, "1-3,7"},
+		{"two values", []int{10, 11}, "10,11"},
+		{"step not until the end", []int{0, 10, 20}, "0,10,20"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, CompactValues(tc.input, []int{0, 59}))
+		})
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.Nil(t, err)
+	start := time.Date(2021, time.January, 1, 0, 0, 0, 0, loc)
+	expected := []time.Time{
+		time.Date(2021, time.March, 28, 1, 0, 0, 0, time.UTC),
+		time.Date(2021, time.October, 31, 1, 0, 0, 0, time.UTC),
+	}
+	actual := Transitions(start, start.AddDate(1, 0, 0))
+	require.Len(t, actual, 2)
+	for i := range expected {
+		assert.True(t, expected[i].Equal(actual[i]))
+	}
+	assert.Empty(t, Transitions(start.UTC(), start.AddDate(1, 0, 0)))
+}